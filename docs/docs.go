@@ -19,56 +19,93 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/event": {
+        "/devices/claim": {
             "post": {
-                "description": "Event endpoint to be called from chirpstack",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Reassigns a preregistered inventory device to the requesting user. The claim code can only be used once.",
                 "consumes": [
-                    "application/json",
-                    "application/x-protobuf",
-                    "application/octet-stream"
+                    "application/json"
                 ],
-                "summary": "Event",
+                "tags": [
+                    "Claims"
+                ],
+                "summary": "Claim Device",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Platform User ID",
-                        "name": "X-UserId",
-                        "in": "header",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Event Type ('up'/'join'/'status')",
-                        "name": "event",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "description": "uplink event",
-                        "name": "uplink",
+                        "description": "dev eui and claim code",
+                        "name": "claim",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/integration.UplinkEvent"
+                            "$ref": "#/definitions/model.DeviceClaimRequest"
                         }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "claimed device",
+                        "schema": {
+                            "$ref": "#/definitions/model.DeviceClaimResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
                     },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/devices/import": {
+            "post": {
+                "security": [
                     {
-                        "description": "status event",
-                        "name": "uplink",
+                        "Bearer": []
+                    }
+                ],
+                "description": "Validates a device manifest and creates the platform and chirpstack devices in a background job.\nCSV manifests need a header row using the json field names of model.DeviceImportRow.\nIf any row is invalid, nothing is created and the rejected job is returned with status code 400.",
+                "consumes": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Devices"
+                ],
+                "summary": "Import Devices",
+                "parameters": [
+                    {
+                        "description": "device manifest",
+                        "name": "manifest",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/integration.StatusEvent"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.DeviceImportRow"
+                            }
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "status message (or null)",
+                    "202": {
+                        "description": "started import job",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.DeviceImportJob"
                         }
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "rejected import job",
+                        "schema": {
+                            "$ref": "#/definitions/model.DeviceImportJob"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error"
@@ -76,763 +113,2387 @@ const docTemplate = `{
                 }
             }
         },
-        "/gateways/{hub_id}/cert": {
-            "post": {
+        "/devices/import/{job_id}": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Generates a new certificate",
+                "description": "Returns the state and per-row results of an import job",
                 "tags": [
-                    "Gateways"
+                    "Devices"
                 ],
-                "summary": "Generate Certificate",
+                "summary": "Device Import Job",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Hub ID",
-                        "name": "hub_id",
+                        "description": "Job ID",
+                        "name": "job_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "generated certificate",
+                        "description": "import job",
                         "schema": {
-                            "$ref": "#/definitions/model.Certs"
+                            "$ref": "#/definitions/model.DeviceImportJob"
                         }
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/provision": {
+        "/devices/validate": {
             "post": {
-                "description": "Runs the provision for a new user",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Validates a proposed platform device before it is saved. Devices with an id are validated as update of the existing device.",
                 "consumes": [
                     "application/json"
                 ],
-                "summary": "Provision",
+                "tags": [
+                    "Devices"
+                ],
+                "summary": "Validate Device",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "newly creted user id",
-                        "name": "user_id",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "description": "user info",
-                        "name": "payload",
+                        "description": "proposed device",
+                        "name": "device",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/gocloak.UserInfo"
+                            "$ref": "#/definitions/models.Device"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "status message (or null)",
+                        "description": "validation result",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.DeviceValidationResult"
                         }
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/sync/device-profiles": {
-            "patch": {
+        "/devices/{device_id}/claim-code": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Syncs all device profiles",
+                "description": "Attaches a new one-time claim code to an inventory device, replacing any previous code. The code is only returned once. Requires admin privileges.",
                 "tags": [
-                    "Sync"
+                    "Claims"
+                ],
+                "summary": "Create Claim Code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "device_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Sync Device Profiles",
                 "responses": {
                     "200": {
-                        "description": "status message (or null)",
+                        "description": "claim code",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.DeviceClaimCode"
                         }
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            }
-        },
-        "/sync/devices": {
-            "patch": {
+            },
+            "delete": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Syncs all devices",
+                "description": "Revokes the claim code of an inventory device. Requires admin privileges.",
                 "tags": [
-                    "Sync"
+                    "Claims"
+                ],
+                "summary": "Delete Claim Code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "device_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Sync Devices",
                 "responses": {
                     "200": {
-                        "description": "status message (or null)",
-                        "schema": {
-                            "type": "string"
-                        }
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/sync/gateways": {
-            "patch": {
+        "/devices/{device_id}/keys": {
+            "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Syncs all gateways",
+                "description": "Pushes new LoRaWAN keys of a device to chirpstack. Empty keys remain unchanged.\nIf write-only keys are enabled, the key attributes of the platform device only contain fingerprints afterwards.\nResponds with 409, if the chirpstack device belongs to another tenant.",
+                "consumes": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Sync"
+                    "Devices"
                 ],
-                "summary": "Sync Gateways",
-                "responses": {
-                    "200": {
-                        "description": "status message (or null)",
+                "summary": "Set Device Keys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "device_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new keys",
+                        "name": "keys",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.DeviceKeys"
                         }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/sync/users": {
-            "patch": {
+        "/devices/{device_id}/lora": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Syncs all users",
+                "description": "Returns the LoRaWAN runtime state of a device as known to chirpstack",
                 "tags": [
-                    "Sync"
+                    "Devices"
+                ],
+                "summary": "LoRaWAN Device Status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "device_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Sync Users",
                 "responses": {
                     "200": {
-                        "description": "status message (or null)",
+                        "description": "device status",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.DeviceLoraStatus"
                         }
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "common.DeviceClass": {
-            "type": "integer",
-            "format": "int32",
-            "enum": [
-                0,
-                1,
-                2
-            ],
-            "x-enum-varnames": [
-                "DeviceClass_CLASS_A",
-                "DeviceClass_CLASS_B",
-                "DeviceClass_CLASS_C"
-            ]
         },
-        "common.JoinServerContext": {
-            "type": "object",
-            "properties": {
-                "app_s_key": {
-                    "description": "AppSKey envelope.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/common.KeyEnvelope"
+        "/devices/{device_id}/rotate-keys": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns the latest key rotation of a device",
+                "tags": [
+                    "Devices"
+                ],
+                "summary": "Device Key Rotation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "device_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "key rotation",
+                        "schema": {
+                            "$ref": "#/definitions/model.KeyRotation"
                         }
-                    ]
-                },
-                "session_key_id": {
-                    "description": "Session-key ID.",
-                    "type": "string"
-                }
-            }
-        },
-        "common.KeyEnvelope": {
-            "type": "object",
-            "properties": {
-                "aes_key": {
-                    "description": "AES key (when the kek_label is set, this value must first be decrypted).",
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
                     }
-                },
-                "kek_label": {
-                    "description": "KEK label.",
-                    "type": "string"
                 }
-            }
-        },
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Replaces the root keys of an OTAA device, resets its joined state and tracks the rotation until the next join.\nKeys are generated, if not supplied. Generated keys are only returned once.\nIf rejoin is set, a vendor specific downlink is enqueued within the current session, otherwise the session is removed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Devices"
+                ],
+                "summary": "Rotate Device Keys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "device_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "rotation request",
+                        "name": "rotation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.KeyRotationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "key rotation",
+                        "schema": {
+                            "$ref": "#/definitions/model.KeyRotationResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/devices/{device_id}/transfer": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Moves a chirpstack device flagged as duplicate into the application of the platform device owner, keeping keys and activation. Requires admin privileges.",
+                "tags": [
+                    "Transfers"
+                ],
+                "summary": "Transfer Device",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "device_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/event": {
+            "post": {
+                "description": "Event endpoint to be called from chirpstack",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/octet-stream"
+                ],
+                "summary": "Event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Platform User ID",
+                        "name": "X-UserId",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event Type ('up'/'join'/'status')",
+                        "name": "event",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "uplink event",
+                        "name": "uplink",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/integration.UplinkEvent"
+                        }
+                    },
+                    {
+                        "description": "status event",
+                        "name": "uplink",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/integration.StatusEvent"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "status message (or null)",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/gateways/{hub_id}/cert": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Generates a new certificate",
+                "tags": [
+                    "Gateways"
+                ],
+                "summary": "Generate Certificate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hub ID",
+                        "name": "hub_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "generated certificate",
+                        "schema": {
+                            "$ref": "#/definitions/model.Certs"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/gateways/{hub_id}/transfer": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Moves a chirpstack gateway flagged as duplicate into the tenant of the hub owner. Requires admin privileges.",
+                "tags": [
+                    "Transfers"
+                ],
+                "summary": "Transfer Gateway",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hub ID",
+                        "name": "hub_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/provision": {
+            "post": {
+                "description": "Runs the provision for a new user",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Provision",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "newly creted user id",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "user info",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gocloak.UserInfo"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "status message (or null)",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/secrets/audit": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Lists accesses to the secret store, newest first. Requires admin privileges.",
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Secret Audit Trail",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "limit, default 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "offset, default 0",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "audit entries",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SecretAuditEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/secrets/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Re-encrypts all secrets with the first configured master key. Older master keys may be removed from the configuration afterwards. Requires admin privileges.",
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Rotate Master Key",
+                "responses": {
+                    "200": {
+                        "description": "number of rotated secrets",
+                        "schema": {
+                            "$ref": "#/definitions/model.SecretRotation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/sync/device-profiles": {
+            "patch": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Syncs all device profiles",
+                "tags": [
+                    "Sync"
+                ],
+                "summary": "Sync Device Profiles",
+                "responses": {
+                    "200": {
+                        "description": "status message (or null)",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/sync/devices": {
+            "patch": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Syncs all devices",
+                "tags": [
+                    "Sync"
+                ],
+                "summary": "Sync Devices",
+                "responses": {
+                    "200": {
+                        "description": "status message (or null)",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/sync/gateways": {
+            "patch": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Syncs all gateways",
+                "tags": [
+                    "Sync"
+                ],
+                "summary": "Sync Gateways",
+                "responses": {
+                    "200": {
+                        "description": "status message (or null)",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/sync/users": {
+            "patch": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Syncs all users",
+                "tags": [
+                    "Sync"
+                ],
+                "summary": "Sync Users",
+                "responses": {
+                    "200": {
+                        "description": "status message (or null)",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/transfers": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Lists recorded device and gateway transfers, newest first. Requires admin privileges.",
+                "tags": [
+                    "Transfers"
+                ],
+                "summary": "List Transfers",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "limit, default 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "offset, default 0",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "transfers",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Transfer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/uplinks/dead-letters": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Lists buffered uplinks, which could not be handled after all retries, oldest first. Requires admin privileges.",
+                "tags": [
+                    "Uplinks"
+                ],
+                "summary": "List Dead Letters",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "limit, default 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "offset, default 0",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "dead letters",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.DeadLetter"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/uplinks/dead-letters/replay": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Moves dead letters back to the uplink buffer. All dead letters are replayed, if no id is given. Requires admin privileges.",
+                "tags": [
+                    "Uplinks"
+                ],
+                "summary": "Replay Dead Letters",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "dead letter ids",
+                        "name": "id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "number of replayed dead letters",
+                        "schema": {
+                            "$ref": "#/definitions/model.DeadLetterReplay"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Lists the webhook subscriptions of the requesting user. Secrets are not returned.",
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List Webhooks",
+                "responses": {
+                    "200": {
+                        "description": "webhooks",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WebhookSubscription"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Subscribes a url to the normalized uplink, join and status events of all devices of the requesting user. Deliveries are signed in the X-Webhook-Signature header \"sha256=\u003chex encoded hmac-sha256 of X-Webhook-Timestamp + '.' + body\u003e\". The secret is generated if empty and only returned once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Create Webhook",
+                "parameters": [
+                    {
+                        "description": "url, optional secret and filters",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookSubscription"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "created webhook including secret",
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "409": {
+                        "description": "webhooks of the user have been changed concurrently"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Replaces url and filters of a webhook subscription. The secret is kept, unless a new one is given.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Update Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "url, optional secret and filters",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookSubscription"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "updated webhook",
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Removes a webhook subscription and its delivery log.",
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Lists the latest delivery attempts of a webhook subscription, newest first.",
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List Webhook Deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit, default 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "offset, default 0",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "delivery attempts",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "common.DeviceClass": {
+            "type": "integer",
+            "format": "int32",
+            "enum": [
+                0,
+                1,
+                2
+            ],
+            "x-enum-varnames": [
+                "DeviceClass_CLASS_A",
+                "DeviceClass_CLASS_B",
+                "DeviceClass_CLASS_C"
+            ]
+        },
+        "common.JoinServerContext": {
+            "type": "object",
+            "properties": {
+                "app_s_key": {
+                    "description": "AppSKey envelope.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/common.KeyEnvelope"
+                        }
+                    ]
+                },
+                "session_key_id": {
+                    "description": "Session-key ID.",
+                    "type": "string"
+                }
+            }
+        },
+        "common.KeyEnvelope": {
+            "type": "object",
+            "properties": {
+                "aes_key": {
+                    "description": "AES key (when the kek_label is set, this value must first be decrypted).",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "kek_label": {
+                    "description": "KEK label.",
+                    "type": "string"
+                }
+            }
+        },
         "common.Location": {
             "type": "object",
             "properties": {
-                "accuracy": {
-                    "description": "Accuracy.",
-                    "type": "number"
+                "accuracy": {
+                    "description": "Accuracy.",
+                    "type": "number"
+                },
+                "altitude": {
+                    "description": "Altitude.",
+                    "type": "number"
+                },
+                "latitude": {
+                    "description": "Latitude.",
+                    "type": "number"
+                },
+                "longitude": {
+                    "description": "Longitude.",
+                    "type": "number"
+                },
+                "source": {
+                    "description": "Location source.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/common.LocationSource"
+                        }
+                    ]
+                }
+            }
+        },
+        "common.LocationSource": {
+            "type": "integer",
+            "format": "int32",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4,
+                5,
+                6
+            ],
+            "x-enum-varnames": [
+                "LocationSource_UNKNOWN",
+                "LocationSource_GPS",
+                "LocationSource_CONFIG",
+                "LocationSource_GEO_RESOLVER_TDOA",
+                "LocationSource_GEO_RESOLVER_RSSI",
+                "LocationSource_GEO_RESOLVER_GNSS",
+                "LocationSource_GEO_RESOLVER_WIFI"
+            ]
+        },
+        "durationpb.Duration": {
+            "type": "object",
+            "properties": {
+                "nanos": {
+                    "description": "Signed fractions of a second at nanosecond resolution of the span\nof time. Durations less than one second are represented with a 0\n` + "`" + `seconds` + "`" + ` field and a positive or negative ` + "`" + `nanos` + "`" + ` field. For durations\nof one second or more, a non-zero value for the ` + "`" + `nanos` + "`" + ` field must be\nof the same sign as the ` + "`" + `seconds` + "`" + ` field. Must be from -999,999,999\nto +999,999,999 inclusive.",
+                    "type": "integer"
+                },
+                "seconds": {
+                    "description": "Signed seconds of the span of time. Must be from -315,576,000,000\nto +315,576,000,000 inclusive. Note: these bounds are computed from:\n60 sec/min * 60 min/hr * 24 hr/day * 365.25 days/year * 10000 years",
+                    "type": "integer"
+                }
+            }
+        },
+        "gocloak.UserInfo": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "$ref": "#/definitions/gocloak.UserInfoAddress"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "family_name": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "given_name": {
+                    "type": "string"
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "middle_name": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nickname": {
+                    "type": "string"
+                },
+                "phone_number": {
+                    "type": "string"
+                },
+                "phone_number_verified": {
+                    "type": "boolean"
+                },
+                "picture": {
+                    "type": "string"
+                },
+                "preferred_username": {
+                    "type": "string"
+                },
+                "profile": {
+                    "type": "string"
+                },
+                "sub": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "integer"
+                },
+                "website": {
+                    "type": "string"
+                },
+                "zoneinfo": {
+                    "type": "string"
+                }
+            }
+        },
+        "gocloak.UserInfoAddress": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "type": "string"
+                },
+                "formatted": {
+                    "type": "string"
+                },
+                "locality": {
+                    "type": "string"
+                },
+                "postal_code": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "street_address": {
+                    "type": "string"
+                }
+            }
+        },
+        "gw.CRCStatus": {
+            "type": "integer",
+            "format": "int32",
+            "enum": [
+                0,
+                1,
+                2
+            ],
+            "x-enum-varnames": [
+                "CRCStatus_NO_CRC",
+                "CRCStatus_BAD_CRC",
+                "CRCStatus_CRC_OK"
+            ]
+        },
+        "gw.Modulation": {
+            "type": "object",
+            "properties": {
+                "parameters": {
+                    "description": "Types that are valid to be assigned to Parameters:\n\n\t*Modulation_Lora\n\t*Modulation_Fsk\n\t*Modulation_LrFhss"
+                }
+            }
+        },
+        "gw.UplinkRxInfo": {
+            "type": "object",
+            "properties": {
+                "antenna": {
+                    "description": "Antenna.",
+                    "type": "integer"
+                },
+                "board": {
+                    "description": "Board.",
+                    "type": "integer"
+                },
+                "channel": {
+                    "description": "Channel.",
+                    "type": "integer"
+                },
+                "context": {
+                    "description": "Gateway specific context.\nThis value must be returned to the gateway on (Class-A) downlink.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "crc_status": {
+                    "description": "CRC status.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/gw.CRCStatus"
+                        }
+                    ]
+                },
+                "fine_time_since_gps_epoch": {
+                    "description": "Fine-timestamp.\nThis timestamp can be used for TDOA based geolocation.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/durationpb.Duration"
+                        }
+                    ]
+                },
+                "gateway_id": {
+                    "description": "Gateway ID.",
+                    "type": "string"
+                },
+                "gw_time": {
+                    "description": "Gateway RX time (set if the gateway has a GNSS module).",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/timestamppb.Timestamp"
+                        }
+                    ]
+                },
+                "location": {
+                    "description": "Location.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/common.Location"
+                        }
+                    ]
+                },
+                "metadata": {
+                    "description": "Additional gateway meta-data.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "ns_time": {
+                    "description": "Network Server RX time (set by the NS on receiving the uplink).",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/timestamppb.Timestamp"
+                        }
+                    ]
+                },
+                "rf_chain": {
+                    "description": "RF chain.",
+                    "type": "integer"
+                },
+                "rssi": {
+                    "description": "RSSI.",
+                    "type": "integer"
+                },
+                "snr": {
+                    "description": "SNR.\nNote: only available for LoRa modulation.",
+                    "type": "number"
+                },
+                "time_since_gps_epoch": {
+                    "description": "RX time as time since GPS epoch (set if the gateway has a GNSS module).",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/durationpb.Duration"
+                        }
+                    ]
+                },
+                "uplink_id": {
+                    "description": "Uplink ID.",
+                    "type": "integer"
+                }
+            }
+        },
+        "gw.UplinkTxInfo": {
+            "type": "object",
+            "properties": {
+                "frequency": {
+                    "description": "Frequency (Hz).",
+                    "type": "integer"
+                },
+                "modulation": {
+                    "description": "Modulation.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/gw.Modulation"
+                        }
+                    ]
+                }
+            }
+        },
+        "integration.DeviceInfo": {
+            "type": "object",
+            "properties": {
+                "application_id": {
+                    "description": "Application ID (UUID).",
+                    "type": "string"
+                },
+                "application_name": {
+                    "description": "Application name.",
+                    "type": "string"
+                },
+                "dev_eui": {
+                    "description": "Device EUI.",
+                    "type": "string"
+                },
+                "device_class_enabled": {
+                    "description": "Device class.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/common.DeviceClass"
+                        }
+                    ]
+                },
+                "device_name": {
+                    "description": "Device name.",
+                    "type": "string"
+                },
+                "device_profile_id": {
+                    "description": "Device-profile ID (UUID).",
+                    "type": "string"
+                },
+                "device_profile_name": {
+                    "description": "Device-profile name.",
+                    "type": "string"
+                },
+                "tags": {
+                    "description": "Device-profile and device tags.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "tenant_id": {
+                    "description": "Tenant ID (UUID).",
+                    "type": "string"
+                },
+                "tenant_name": {
+                    "description": "Tenant name.",
+                    "type": "string"
+                }
+            }
+        },
+        "integration.StatusEvent": {
+            "type": "object",
+            "properties": {
+                "battery_level": {
+                    "description": "Battery level.",
+                    "type": "number"
+                },
+                "battery_level_unavailable": {
+                    "description": "Battery level is not available.",
+                    "type": "boolean"
+                },
+                "deduplication_id": {
+                    "description": "Deduplication ID (UUID).",
+                    "type": "string"
+                },
+                "device_info": {
+                    "description": "Device info.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/integration.DeviceInfo"
+                        }
+                    ]
+                },
+                "external_power_source": {
+                    "description": "Device is connected to an external power source.",
+                    "type": "boolean"
+                },
+                "margin": {
+                    "description": "The demodulation signal-to-noise ratio in dB for the last successfully\nreceived device-status request by the Network Server.",
+                    "type": "integer"
+                },
+                "time": {
+                    "description": "Timestamp.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/timestamppb.Timestamp"
+                        }
+                    ]
+                }
+            }
+        },
+        "integration.UplinkEvent": {
+            "type": "object",
+            "properties": {
+                "adr": {
+                    "description": "Device has ADR bit set.",
+                    "type": "boolean"
                 },
-                "altitude": {
-                    "description": "Altitude.",
-                    "type": "number"
+                "confirmed": {
+                    "description": "Uplink was of type confirmed.",
+                    "type": "boolean"
+                },
+                "data": {
+                    "description": "FRMPayload data.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "deduplication_id": {
+                    "description": "Deduplication ID (UUID).",
+                    "type": "string"
+                },
+                "dev_addr": {
+                    "description": "Device address.",
+                    "type": "string"
+                },
+                "device_info": {
+                    "description": "Device information.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/integration.DeviceInfo"
+                        }
+                    ]
+                },
+                "dr": {
+                    "description": "Data-rate.",
+                    "type": "integer"
+                },
+                "f_cnt": {
+                    "description": "Frame counter.",
+                    "type": "integer"
+                },
+                "f_port": {
+                    "description": "Frame port.",
+                    "type": "integer"
+                },
+                "join_server_context": {
+                    "description": "Join-Server context.\nA non-empty value indicatest that ChirpStack does not have access to\nthe AppSKey and that the encryption / decryption of the payloads is\nthe responsibility of the end-application.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/common.JoinServerContext"
+                        }
+                    ]
+                },
+                "object": {
+                    "description": "Note that this is only set when a codec is configured in the Device\nProfile.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/structpb.Struct"
+                        }
+                    ]
+                },
+                "region_config_id": {
+                    "description": "Region config ID.\nThis contains the region config ID which reported the uplink.",
+                    "type": "string"
+                },
+                "relay_rx_info": {
+                    "description": "Relay info.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/integration.UplinkRelayRxInfo"
+                        }
+                    ]
+                },
+                "rx_info": {
+                    "description": "Receiving gateway RX info.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gw.UplinkRxInfo"
+                    }
+                },
+                "time": {
+                    "description": "Timestamp.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/timestamppb.Timestamp"
+                        }
+                    ]
+                },
+                "tx_info": {
+                    "description": "TX info.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/gw.UplinkTxInfo"
+                        }
+                    ]
+                }
+            }
+        },
+        "integration.UplinkRelayRxInfo": {
+            "type": "object",
+            "properties": {
+                "dev_eui": {
+                    "description": "Relay DevEUI.",
+                    "type": "string"
+                },
+                "dr": {
+                    "description": "Data-rate.",
+                    "type": "integer"
+                },
+                "frequency": {
+                    "description": "Frequency.",
+                    "type": "integer"
+                },
+                "rssi": {
+                    "description": "RSSI.",
+                    "type": "integer"
+                },
+                "snr": {
+                    "description": "SNR.",
+                    "type": "integer"
+                },
+                "wor_channel": {
+                    "description": "WOR channel.",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.BufferedEvent": {
+            "type": "object",
+            "properties": {
+                "deduplication_id": {
+                    "type": "string"
+                },
+                "device_profile_id": {
+                    "type": "string"
+                },
+                "local_device_id": {
+                    "type": "string"
+                },
+                "local_service_id": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "rx_info": {
+                    "description": "protojson encoded gw.UplinkRxInfo",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "time": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Certs": {
+            "type": "object",
+            "properties": {
+                "certificate": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DeadLetter": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "$ref": "#/definitions/model.BufferedEvent"
+                },
+                "failed_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DeadLetterReplay": {
+            "type": "object",
+            "properties": {
+                "replayed": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.DeviceClaimCode": {
+            "type": "object",
+            "properties": {
+                "claim_code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "dev_eui": {
+                    "type": "string"
+                },
+                "device_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DeviceClaimRequest": {
+            "type": "object",
+            "properties": {
+                "claim_code": {
+                    "type": "string"
+                },
+                "dev_eui": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DeviceClaimResult": {
+            "type": "object",
+            "properties": {
+                "dev_eui": {
+                    "type": "string"
+                },
+                "device_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DeviceImportJob": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
                 },
-                "latitude": {
-                    "description": "Latitude.",
-                    "type": "number"
+                "id": {
+                    "type": "string"
                 },
-                "longitude": {
-                    "description": "Longitude.",
-                    "type": "number"
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DeviceImportResult"
+                    }
                 },
-                "source": {
-                    "description": "Location source.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/common.LocationSource"
-                        }
-                    ]
+                "status": {
+                    "$ref": "#/definitions/model.DeviceImportJobStatus"
+                },
+                "user_id": {
+                    "type": "string"
                 }
             }
         },
-        "common.LocationSource": {
-            "type": "integer",
-            "format": "int32",
+        "model.DeviceImportJobStatus": {
+            "type": "string",
             "enum": [
-                0,
-                1,
-                2,
-                3,
-                4,
-                5,
-                6
+                "rejected",
+                "running",
+                "done"
             ],
             "x-enum-varnames": [
-                "LocationSource_UNKNOWN",
-                "LocationSource_GPS",
-                "LocationSource_CONFIG",
-                "LocationSource_GEO_RESOLVER_TDOA",
-                "LocationSource_GEO_RESOLVER_RSSI",
-                "LocationSource_GEO_RESOLVER_GNSS",
-                "LocationSource_GEO_RESOLVER_WIFI"
+                "DeviceImportJobStatusRejected",
+                "DeviceImportJobStatusRunning",
+                "DeviceImportJobStatusDone"
             ]
         },
-        "durationpb.Duration": {
+        "model.DeviceImportResult": {
             "type": "object",
             "properties": {
-                "nanos": {
-                    "description": "Signed fractions of a second at nanosecond resolution of the span\nof time. Durations less than one second are represented with a 0\n` + "`" + `seconds` + "`" + ` field and a positive or negative ` + "`" + `nanos` + "`" + ` field. For durations\nof one second or more, a non-zero value for the ` + "`" + `nanos` + "`" + ` field must be\nof the same sign as the ` + "`" + `seconds` + "`" + ` field. Must be from -999,999,999\nto +999,999,999 inclusive.",
-                    "type": "integer"
+                "dev_eui": {
+                    "type": "string"
                 },
-                "seconds": {
-                    "description": "Signed seconds of the span of time. Must be from -315,576,000,000\nto +315,576,000,000 inclusive. Note: these bounds are computed from:\n60 sec/min * 60 min/hr * 24 hr/day * 365.25 days/year * 10000 years",
+                "device_id": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DeviceValidationError"
+                    }
+                },
+                "row": {
+                    "description": "1-based index in the manifest, excluding a csv header",
                     "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.DeviceImportResultStatus"
                 }
             }
         },
-        "gocloak.UserInfo": {
+        "model.DeviceImportResultStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "invalid",
+                "created",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "DeviceImportResultStatusPending",
+                "DeviceImportResultStatusInvalid",
+                "DeviceImportResultStatusCreated",
+                "DeviceImportResultStatusFailed"
+            ]
+        },
+        "model.DeviceImportRow": {
             "type": "object",
             "properties": {
-                "address": {
-                    "$ref": "#/definitions/gocloak.UserInfoAddress"
-                },
-                "email": {
+                "app_key": {
                     "type": "string"
                 },
-                "email_verified": {
-                    "type": "boolean"
+                "app_s_key": {
+                    "type": "string"
                 },
-                "family_name": {
+                "dev_addr": {
                     "type": "string"
                 },
-                "gender": {
+                "dev_eui": {
                     "type": "string"
                 },
-                "given_name": {
+                "device_profile_id": {
                     "type": "string"
                 },
-                "locale": {
+                "f_nwk_s_int_key": {
                     "type": "string"
                 },
-                "middle_name": {
+                "join_eui": {
                     "type": "string"
                 },
                 "name": {
                     "type": "string"
                 },
-                "nickname": {
+                "nwk_key": {
                     "type": "string"
                 },
-                "phone_number": {
+                "nwk_s_enc_key": {
                     "type": "string"
                 },
-                "phone_number_verified": {
-                    "type": "boolean"
-                },
-                "picture": {
+                "s_nwk_s_int_key": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DeviceKeys": {
+            "type": "object",
+            "properties": {
+                "app_key": {
                     "type": "string"
                 },
-                "preferred_username": {
+                "app_s_key": {
                     "type": "string"
                 },
-                "profile": {
+                "f_nwk_s_int_key": {
                     "type": "string"
                 },
-                "sub": {
+                "gen_app_key": {
                     "type": "string"
                 },
-                "updated_at": {
-                    "type": "integer"
+                "nwk_key": {
+                    "type": "string"
                 },
-                "website": {
+                "nwk_s_enc_key": {
                     "type": "string"
                 },
-                "zoneinfo": {
+                "s_nwk_s_int_key": {
                     "type": "string"
                 }
             }
         },
-        "gocloak.UserInfoAddress": {
+        "model.DeviceLoraActivation": {
             "type": "object",
             "properties": {
-                "country": {
-                    "type": "string"
+                "a_f_cnt_down": {
+                    "type": "integer"
                 },
-                "formatted": {
+                "dev_addr": {
                     "type": "string"
                 },
-                "locality": {
-                    "type": "string"
+                "f_cnt_up": {
+                    "type": "integer"
                 },
-                "postal_code": {
-                    "type": "string"
+                "n_f_cnt_down": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.DeviceLoraDeviceStatus": {
+            "type": "object",
+            "properties": {
+                "battery_level": {
+                    "description": "-1 if unavailable",
+                    "type": "number"
                 },
-                "region": {
-                    "type": "string"
+                "external_power_source": {
+                    "type": "boolean"
                 },
-                "street_address": {
-                    "type": "string"
+                "margin": {
+                    "type": "integer"
                 }
             }
         },
-        "gw.CRCStatus": {
-            "type": "integer",
-            "format": "int32",
-            "enum": [
-                0,
-                1,
-                2
-            ],
-            "x-enum-varnames": [
-                "CRCStatus_NO_CRC",
-                "CRCStatus_BAD_CRC",
-                "CRCStatus_CRC_OK"
-            ]
-        },
-        "gw.Modulation": {
+        "model.DeviceLoraGateway": {
             "type": "object",
             "properties": {
-                "parameters": {
-                    "description": "Types that are valid to be assigned to Parameters:\n\n\t*Modulation_Lora\n\t*Modulation_Fsk\n\t*Modulation_LrFhss"
+                "gateway_id": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
                 }
             }
         },
-        "gw.UplinkRxInfo": {
+        "model.DeviceLoraStatus": {
             "type": "object",
             "properties": {
-                "antenna": {
-                    "description": "Antenna.",
-                    "type": "integer"
+                "activation": {
+                    "$ref": "#/definitions/model.DeviceLoraActivation"
                 },
-                "board": {
-                    "description": "Board.",
-                    "type": "integer"
+                "class_enabled": {
+                    "type": "string"
                 },
-                "channel": {
-                    "description": "Channel.",
-                    "type": "integer"
+                "dev_eui": {
+                    "type": "string"
                 },
-                "context": {
-                    "description": "Gateway specific context.\nThis value must be returned to the gateway on (Class-A) downlink.",
+                "device_id": {
+                    "type": "string"
+                },
+                "device_status": {
+                    "$ref": "#/definitions/model.DeviceLoraDeviceStatus"
+                },
+                "duplicate": {
+                    "type": "boolean"
+                },
+                "gateways": {
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "$ref": "#/definitions/model.DeviceLoraGateway"
                     }
                 },
-                "crc_status": {
-                    "description": "CRC status.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/gw.CRCStatus"
-                        }
-                    ]
+                "joined": {
+                    "type": "boolean"
                 },
-                "fine_time_since_gps_epoch": {
-                    "description": "Fine-timestamp.\nThis timestamp can be used for TDOA based geolocation.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/durationpb.Duration"
-                        }
-                    ]
+                "last_seen_at": {
+                    "type": "string"
                 },
-                "gateway_id": {
-                    "description": "Gateway ID.",
+                "registered": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.DeviceValidationError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "attribute key or device field",
                     "type": "string"
                 },
-                "gw_time": {
-                    "description": "Gateway RX time (set if the gateway has a GNSS module).",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/timestamppb.Timestamp"
-                        }
-                    ]
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DeviceValidationResult": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DeviceValidationError"
+                    }
                 },
-                "location": {
-                    "description": "Location.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/common.Location"
-                        }
-                    ]
+                "valid": {
+                    "type": "boolean"
                 },
-                "metadata": {
-                    "description": "Additional gateway meta-data.",
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DeviceValidationError"
                     }
+                }
+            }
+        },
+        "model.KeyRotation": {
+            "type": "object",
+            "properties": {
+                "confirmed_at": {
+                    "type": "string"
                 },
-                "ns_time": {
-                    "description": "Network Server RX time (set by the NS on receiving the uplink).",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/timestamppb.Timestamp"
-                        }
-                    ]
+                "dev_eui": {
+                    "type": "string"
                 },
-                "rf_chain": {
-                    "description": "RF chain.",
-                    "type": "integer"
+                "device_id": {
+                    "type": "string"
                 },
-                "rssi": {
-                    "description": "RSSI.",
-                    "type": "integer"
+                "rejoin_downlink_id": {
+                    "type": "string"
                 },
-                "snr": {
-                    "description": "SNR.\nNote: only available for LoRa modulation.",
-                    "type": "number"
+                "requested_at": {
+                    "type": "string"
                 },
-                "time_since_gps_epoch": {
-                    "description": "RX time as time since GPS epoch (set if the gateway has a GNSS module).",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/durationpb.Duration"
-                        }
-                    ]
+                "requested_by": {
+                    "type": "string"
                 },
-                "uplink_id": {
-                    "description": "Uplink ID.",
-                    "type": "integer"
+                "status": {
+                    "$ref": "#/definitions/model.KeyRotationStatus"
                 }
             }
         },
-        "gw.UplinkTxInfo": {
+        "model.KeyRotationRequest": {
             "type": "object",
             "properties": {
-                "frequency": {
-                    "description": "Frequency (Hz).",
+                "app_key": {
+                    "description": "generated, if empty",
+                    "type": "string"
+                },
+                "nwk_key": {
+                    "description": "generated, if empty",
+                    "type": "string"
+                },
+                "rejoin": {
+                    "type": "boolean"
+                },
+                "rejoin_f_port": {
+                    "description": "defaults to the device type attribute senergy/lora/rejoin-f-port",
                     "type": "integer"
                 },
-                "modulation": {
-                    "description": "Modulation.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/gw.Modulation"
-                        }
-                    ]
+                "rejoin_payload": {
+                    "description": "hex encoded, defaults to the device type attribute senergy/lora/rejoin-payload",
+                    "type": "string"
                 }
             }
         },
-        "integration.DeviceInfo": {
+        "model.KeyRotationResult": {
             "type": "object",
             "properties": {
-                "application_id": {
-                    "description": "Application ID (UUID).",
+                "confirmed_at": {
                     "type": "string"
                 },
-                "application_name": {
-                    "description": "Application name.",
+                "dev_eui": {
                     "type": "string"
                 },
-                "dev_eui": {
-                    "description": "Device EUI.",
+                "device_id": {
                     "type": "string"
                 },
-                "device_class_enabled": {
-                    "description": "Device class.",
+                "generated_keys": {
+                    "description": "supplied keys are not returned",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/common.DeviceClass"
+                            "$ref": "#/definitions/model.DeviceKeys"
                         }
                     ]
                 },
-                "device_name": {
-                    "description": "Device name.",
+                "rejoin_downlink_id": {
                     "type": "string"
                 },
-                "device_profile_id": {
-                    "description": "Device-profile ID (UUID).",
+                "requested_at": {
                     "type": "string"
                 },
-                "device_profile_name": {
-                    "description": "Device-profile name.",
+                "requested_by": {
                     "type": "string"
                 },
-                "tags": {
-                    "description": "Device-profile and device tags.",
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "status": {
+                    "$ref": "#/definitions/model.KeyRotationStatus"
+                }
+            }
+        },
+        "model.KeyRotationStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "confirmed"
+            ],
+            "x-enum-comments": {
+                "KeyRotationStatusConfirmed": "device joined with the new keys",
+                "KeyRotationStatusPending": "waiting for the device to rejoin"
+            },
+            "x-enum-descriptions": [
+                "waiting for the device to rejoin",
+                "device joined with the new keys"
+            ],
+            "x-enum-varnames": [
+                "KeyRotationStatusPending",
+                "KeyRotationStatusConfirmed"
+            ]
+        },
+        "model.SecretAction": {
+            "type": "string",
+            "enum": [
+                "put",
+                "get",
+                "delete",
+                "rotate"
+            ],
+            "x-enum-varnames": [
+                "SecretActionPut",
+                "SecretActionGet",
+                "SecretActionDelete",
+                "SecretActionRotate"
+            ]
+        },
+        "model.SecretAuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/model.SecretAction"
                 },
-                "tenant_id": {
-                    "description": "Tenant ID (UUID).",
+                "actor": {
                     "type": "string"
                 },
-                "tenant_name": {
-                    "description": "Tenant name.",
+                "master_key_id": {
+                    "type": "string"
+                },
+                "secret_id": {
+                    "type": "string"
+                },
+                "time": {
                     "type": "string"
                 }
             }
         },
-        "integration.StatusEvent": {
+        "model.SecretRotation": {
             "type": "object",
             "properties": {
-                "battery_level": {
-                    "description": "Battery level.",
-                    "type": "number"
-                },
-                "battery_level_unavailable": {
-                    "description": "Battery level is not available.",
-                    "type": "boolean"
+                "rotated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Transfer": {
+            "type": "object",
+            "properties": {
+                "eui": {
+                    "type": "string"
                 },
-                "deduplication_id": {
-                    "description": "Deduplication ID (UUID).",
+                "from_tenant_id": {
                     "type": "string"
                 },
-                "device_info": {
-                    "description": "Device info.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/integration.DeviceInfo"
-                        }
-                    ]
+                "from_user_id": {
+                    "type": "string"
                 },
-                "external_power_source": {
-                    "description": "Device is connected to an external power source.",
-                    "type": "boolean"
+                "platform_id": {
+                    "type": "string"
                 },
-                "margin": {
-                    "description": "The demodulation signal-to-noise ratio in dB for the last successfully\nreceived device-status request by the Network Server.",
-                    "type": "integer"
+                "reason": {
+                    "$ref": "#/definitions/model.TransferReason"
                 },
                 "time": {
-                    "description": "Timestamp.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/timestamppb.Timestamp"
-                        }
-                    ]
+                    "type": "string"
+                },
+                "to_tenant_id": {
+                    "type": "string"
+                },
+                "to_user_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/model.TransferType"
                 }
             }
         },
-        "integration.UplinkEvent": {
+        "model.TransferReason": {
+            "type": "string",
+            "enum": [
+                "deleted",
+                "released",
+                "admin",
+                "claimed"
+            ],
+            "x-enum-comments": {
+                "TransferReasonAdmin": "transfer was approved by an admin",
+                "TransferReasonClaimed": "device was claimed from the inventory with a claim code",
+                "TransferReasonDeleted": "previous owner deleted the platform device or hub",
+                "TransferReasonReleased": "previous owner set the released attribute"
+            },
+            "x-enum-descriptions": [
+                "previous owner deleted the platform device or hub",
+                "previous owner set the released attribute",
+                "transfer was approved by an admin",
+                "device was claimed from the inventory with a claim code"
+            ],
+            "x-enum-varnames": [
+                "TransferReasonDeleted",
+                "TransferReasonReleased",
+                "TransferReasonAdmin",
+                "TransferReasonClaimed"
+            ]
+        },
+        "model.TransferType": {
+            "type": "string",
+            "enum": [
+                "device",
+                "gateway"
+            ],
+            "x-enum-varnames": [
+                "TransferTypeDevice",
+                "TransferTypeGateway"
+            ]
+        },
+        "model.WebhookDelivery": {
             "type": "object",
             "properties": {
-                "adr": {
-                    "description": "Device has ADR bit set.",
-                    "type": "boolean"
-                },
-                "confirmed": {
-                    "description": "Uplink was of type confirmed.",
-                    "type": "boolean"
-                },
-                "data": {
-                    "description": "FRMPayload data.",
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
+                "attempt": {
+                    "type": "integer"
                 },
-                "deduplication_id": {
-                    "description": "Deduplication ID (UUID).",
+                "device_id": {
                     "type": "string"
                 },
-                "dev_addr": {
-                    "description": "Device address.",
+                "error": {
                     "type": "string"
                 },
-                "device_info": {
-                    "description": "Device information.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/integration.DeviceInfo"
-                        }
-                    ]
-                },
-                "dr": {
-                    "description": "Data-rate.",
-                    "type": "integer"
+                "event_id": {
+                    "type": "string"
                 },
-                "f_cnt": {
-                    "description": "Frame counter.",
-                    "type": "integer"
+                "event_type": {
+                    "type": "string"
                 },
-                "f_port": {
-                    "description": "Frame port.",
+                "status_code": {
                     "type": "integer"
                 },
-                "join_server_context": {
-                    "description": "Join-Server context.\nA non-empty value indicatest that ChirpStack does not have access to\nthe AppSKey and that the encryption / decryption of the payloads is\nthe responsibility of the end-application.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/common.JoinServerContext"
-                        }
-                    ]
-                },
-                "object": {
-                    "description": "Note that this is only set when a codec is configured in the Device\nProfile.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/structpb.Struct"
-                        }
-                    ]
+                "success": {
+                    "type": "boolean"
                 },
-                "region_config_id": {
-                    "description": "Region config ID.\nThis contains the region config ID which reported the uplink.",
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.WebhookSubscription": {
+            "type": "object",
+            "properties": {
+                "created_at": {
                     "type": "string"
                 },
-                "relay_rx_info": {
-                    "description": "Relay info.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/integration.UplinkRelayRxInfo"
-                        }
-                    ]
+                "device_ids": {
+                    "description": "optional filter by platform device id",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "rx_info": {
-                    "description": "Receiving gateway RX info.",
+                "events": {
+                    "description": "\"up\", \"join\", \"status\" and/or \"location\", all events if empty",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/gw.UplinkRxInfo"
+                        "type": "string"
                     }
                 },
-                "time": {
-                    "description": "Timestamp.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/timestamppb.Timestamp"
-                        }
-                    ]
+                "id": {
+                    "type": "string"
                 },
-                "tx_info": {
-                    "description": "TX info.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/gw.UplinkTxInfo"
-                        }
-                    ]
+                "secret": {
+                    "description": "key of the hmac signature, generated if empty",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
                 }
             }
         },
-        "integration.UplinkRelayRxInfo": {
+        "models.Attribute": {
             "type": "object",
             "properties": {
-                "dev_eui": {
-                    "description": "Relay DevEUI.",
+                "key": {
                     "type": "string"
                 },
-                "dr": {
-                    "description": "Data-rate.",
-                    "type": "integer"
-                },
-                "frequency": {
-                    "description": "Frequency.",
-                    "type": "integer"
-                },
-                "rssi": {
-                    "description": "RSSI.",
-                    "type": "integer"
-                },
-                "snr": {
-                    "description": "SNR.",
-                    "type": "integer"
+                "origin": {
+                    "type": "string"
                 },
-                "wor_channel": {
-                    "description": "WOR channel.",
-                    "type": "integer"
+                "value": {
+                    "type": "string"
                 }
             }
         },
-        "model.Certs": {
+        "models.Device": {
             "type": "object",
             "properties": {
-                "certificate": {
+                "attributes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Attribute"
+                    }
+                },
+                "device_type_id": {
                     "type": "string"
                 },
-                "expires_at": {
+                "id": {
                     "type": "string"
                 },
-                "key": {
+                "local_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner_id": {
                     "type": "string"
                 }
             }
@@ -22,11 +22,13 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	envldr "github.com/SENERGY-Platform/go-env-loader"
 	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg"
 	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/configuration"
 	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
 	"github.com/chirpstack/chirpstack/api/go/v4/common"
 )
 
@@ -36,6 +38,7 @@ func main() {
 		ChirpstackUrl:      "ui.lora.senergy.infai.org",
 		KeycloakUrl:        "https://auth.senergy.infai.org/auth",
 		KeycloakClientId:   "lorawan-platform-connector",
+		KeycloakRealm:      "master",
 		Host:               "http://connector",
 		LogHandler:         "json",
 		LogLevel:           "info",
@@ -44,19 +47,36 @@ func main() {
 		ChirpstackProtectedUsers: []string{
 			"admin",
 		},
-		KafkaBootstrap:          "kafka.kafka:9092",
-		DeviceRepoUrl:           "http://api.device-repository:8080",
-		PermissionsV2Url:        "http://permv2.permissions:8080",
-		MemcachedUrl:            "memcached:11211",
-		NotificationsUrl:        "http://api.notifier:5000",
-		Regions:                 []int32{int32(common.Region_EU868)},
-		ProtocolId:              "urn:infai:ses:protocol:9c956b2b-c34d-4083-9f8e-d9cc35246137",
-		ProtocolDataSegmentId:   "urn:infai:ses:protocol-segment:7855de22-8643-4fd8-96e2-df8eb6f9948c",
-		BatteryCharacteristicId: "urn:infai:ses:characteristic:062da5dd-085e-4b01-9e13-e331cb97dc0f",
-		BatteryFunctionId:       "urn:infai:ses:measuring-function:00549f18-88b5-44c7-adb1-f558e8d53d1d",
-		BatteryAspectId:         "urn:infai:ses:aspect:81936bcb-3625-4054-9f88-8934ee63d3ca",
-		DeviceClassId:           "urn:infai:ses:device-class:ff64280a-58e6-4cf9-9a44-e70d3831a79d",
-		RedisUrl:                "redis:6379",
+		KafkaBootstrap:           "kafka.kafka:9092",
+		KafkaUserTopic:           "user",
+		DeviceRepoUrl:            "http://api.device-repository:8080",
+		PermissionsV2Url:         "http://permv2.permissions:8080",
+		MemcachedUrl:             "memcached:11211",
+		NotificationsUrl:         "http://api.notifier:5000",
+		Regions:                  []int32{int32(common.Region_EU868)},
+		ProtocolId:               "urn:infai:ses:protocol:9c956b2b-c34d-4083-9f8e-d9cc35246137",
+		ProtocolDataSegmentId:    "urn:infai:ses:protocol-segment:7855de22-8643-4fd8-96e2-df8eb6f9948c",
+		BatteryCharacteristicId:  "urn:infai:ses:characteristic:062da5dd-085e-4b01-9e13-e331cb97dc0f",
+		BatteryFunctionId:        "urn:infai:ses:measuring-function:00549f18-88b5-44c7-adb1-f558e8d53d1d",
+		BatteryAspectId:          "urn:infai:ses:aspect:81936bcb-3625-4054-9f88-8934ee63d3ca",
+		DeviceClassId:            "urn:infai:ses:device-class:ff64280a-58e6-4cf9-9a44-e70d3831a79d",
+		RedisUrl:                 "redis:6379",
+		SecretStoreFile:          "/data/secrets.json",
+		UserDeletionMaxShrink:    10,
+		TagAttributePrefix:       "senergy/lora/tag/",
+		VariableAttributePrefix:  "senergy/lora/var/",
+		DeviceTypeUpdateDebounce: 5 * time.Second,
+		UplinkBufferWorkers:      4,
+		UplinkBufferMaxAttempts:  10,
+		UplinkBufferMaxBackoff:   time.Minute,
+		UplinkBufferDeadLetters:  10000,
+		DeduplicationTtl:         10 * time.Minute,
+		IngestionMode:            model.IngestionModeHttp,
+		ChirpstackEventStream:    "device:stream:event",
+		MqttEventTopic:           "application/+/device/+/event/+",
+		MqttEncoding:             model.EventEncodingJson,
+		WebhookWorkers:           4,
+		WebhookMaxAttempts:       5,
 	}
 
 	// load config from environment
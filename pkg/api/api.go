@@ -46,6 +46,28 @@ var routes = gin_mw.Routes[*controller.Controller]{
 	patchSyncAllDeviceProfiles,
 	patchSyncAllGateways,
 	generateCert,
+	getDeviceLoraStatus,
+	postDeviceImport,
+	getDeviceImportJob,
+	postDeviceValidation,
+	postDeviceTransfer,
+	postGatewayTransfer,
+	getTransfers,
+	postDeviceClaim,
+	postDeviceClaimCode,
+	deleteDeviceClaimCode,
+	putDeviceKeys,
+	postSecretRotation,
+	getSecretAudit,
+	postDeviceKeyRotation,
+	getDeviceKeyRotation,
+	getUplinkDeadLetters,
+	postUplinkDeadLetterReplay,
+	getWebhooks,
+	postWebhook,
+	putWebhook,
+	deleteWebhook,
+	getWebhookDeliveries,
 }
 
 // Start godoc
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/controller"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// postDeviceClaim godoc
+// @Summary      Claim Device
+// @Description  Reassigns a preregistered inventory device to the requesting user. The claim code can only be used once.
+// @Accept       json
+// @Param        claim body model.DeviceClaimRequest true "dev eui and claim code"
+// @Success      200 {object} model.DeviceClaimResult "claimed device"
+// @Failure      400
+// @Failure      403
+// @Failure      500
+// @Tags         Claims
+// @Security     Bearer
+// @Router       /devices/claim [POST]
+func postDeviceClaim(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/devices/claim", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		var request model.DeviceClaimRequest
+		err = gc.ShouldBindJSON(&request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse request body"), err))
+			return
+		}
+		result, err := controller.ClaimDevice(gc.Request.Context(), token, request)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, result)
+	}
+}
+
+// postDeviceClaimCode godoc
+// @Summary      Create Claim Code
+// @Description  Attaches a new one-time claim code to an inventory device, replacing any previous code. The code is only returned once. Requires admin privileges.
+// @Param        device_id path string true "Device ID"
+// @Success      200 {object} model.DeviceClaimCode "claim code"
+// @Failure      400
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Tags         Claims
+// @Security     Bearer
+// @Router       /devices/{device_id}/claim-code [POST]
+func postDeviceClaimCode(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/devices/:device_id/claim-code", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		deviceId := gc.Param("device_id")
+		if deviceId == "" {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("missing path param device_id")))
+			return
+		}
+		claimCode, err := controller.CreateDeviceClaimCode(gc.Request.Context(), token, deviceId)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, claimCode)
+	}
+}
+
+// deleteDeviceClaimCode godoc
+// @Summary      Delete Claim Code
+// @Description  Revokes the claim code of an inventory device. Requires admin privileges.
+// @Param        device_id path string true "Device ID"
+// @Success      200
+// @Failure      400
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Tags         Claims
+// @Security     Bearer
+// @Router       /devices/{device_id}/claim-code [DELETE]
+func deleteDeviceClaimCode(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodDelete, "/devices/:device_id/claim-code", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		deviceId := gc.Param("device_id")
+		if deviceId == "" {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("missing path param device_id")))
+			return
+		}
+		err = controller.DeleteDeviceClaimCode(gc.Request.Context(), token, deviceId)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.Status(http.StatusOK)
+	}
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/controller"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// getDeviceLoraStatus godoc
+// @Summary      LoRaWAN Device Status
+// @Description  Returns the LoRaWAN runtime state of a device as known to chirpstack
+// @Param        device_id path string true "Device ID"
+// @Success      200 {object} model.DeviceLoraStatus "device status"
+// @Failure      400
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Tags         Devices
+// @Security     Bearer
+// @Router       /devices/{device_id}/lora [GET]
+func getDeviceLoraStatus(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/devices/:device_id/lora", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		deviceId := gc.Param("device_id")
+		if deviceId == "" {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("missing path param device_id")))
+			return
+		}
+		deviceStatus, err := controller.GetDeviceLoraStatus(gc.Request.Context(), token, deviceId)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, deviceStatus)
+	}
+}
+
+// postDeviceValidation godoc
+// @Summary      Validate Device
+// @Description  Validates a proposed platform device before it is saved. Devices with an id are validated as update of the existing device.
+// @Accept       json
+// @Param        device body models.Device true "proposed device"
+// @Success      200 {object} model.DeviceValidationResult "validation result"
+// @Failure      400
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Tags         Devices
+// @Security     Bearer
+// @Router       /devices/validate [POST]
+func postDeviceValidation(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/devices/validate", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		var device models.Device
+		err = gc.ShouldBindJSON(&device)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse request body"), err))
+			return
+		}
+		result, err := controller.ValidateDevice(gc.Request.Context(), token, device)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, result)
+	}
+}
+
+// putDeviceKeys godoc
+// @Summary      Set Device Keys
+// @Description  Pushes new LoRaWAN keys of a device to chirpstack. Empty keys remain unchanged.
+// @Description  If write-only keys are enabled, the key attributes of the platform device only contain fingerprints afterwards.
+// @Description  Responds with 409, if the chirpstack device belongs to another tenant.
+// @Accept       json
+// @Param        device_id path string true "Device ID"
+// @Param        keys body model.DeviceKeys true "new keys"
+// @Success      200
+// @Failure      400
+// @Failure      403
+// @Failure      404
+// @Failure      409
+// @Failure      500
+// @Tags         Devices
+// @Security     Bearer
+// @Router       /devices/{device_id}/keys [PUT]
+func putDeviceKeys(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodPut, "/devices/:device_id/keys", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		deviceId := gc.Param("device_id")
+		if deviceId == "" {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("missing path param device_id")))
+			return
+		}
+		var keys model.DeviceKeys
+		err = gc.ShouldBindJSON(&keys)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse request body"), err))
+			return
+		}
+		err = controller.SetDeviceKeys(gc.Request.Context(), token, deviceId, keys)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.Status(http.StatusOK)
+	}
+}
+
+// postDeviceKeyRotation godoc
+// @Summary      Rotate Device Keys
+// @Description  Replaces the root keys of an OTAA device, resets its joined state and tracks the rotation until the next join.
+// @Description  Keys are generated, if not supplied. Generated keys are only returned once.
+// @Description  If rejoin is set, a vendor specific downlink is enqueued within the current session, otherwise the session is removed.
+// @Accept       json
+// @Param        device_id path string true "Device ID"
+// @Param        rotation body model.KeyRotationRequest true "rotation request"
+// @Success      200 {object} model.KeyRotationResult "key rotation"
+// @Failure      400
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Tags         Devices
+// @Security     Bearer
+// @Router       /devices/{device_id}/rotate-keys [POST]
+func postDeviceKeyRotation(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/devices/:device_id/rotate-keys", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		deviceId := gc.Param("device_id")
+		if deviceId == "" {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("missing path param device_id")))
+			return
+		}
+		var request model.KeyRotationRequest
+		err = gc.ShouldBindJSON(&request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse request body"), err))
+			return
+		}
+		result, err := controller.RotateDeviceKeys(gc.Request.Context(), token, deviceId, request)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, result)
+	}
+}
+
+// getDeviceKeyRotation godoc
+// @Summary      Device Key Rotation
+// @Description  Returns the latest key rotation of a device
+// @Param        device_id path string true "Device ID"
+// @Success      200 {object} model.KeyRotation "key rotation"
+// @Failure      400
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Tags         Devices
+// @Security     Bearer
+// @Router       /devices/{device_id}/rotate-keys [GET]
+func getDeviceKeyRotation(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/devices/:device_id/rotate-keys", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		deviceId := gc.Param("device_id")
+		if deviceId == "" {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("missing path param device_id")))
+			return
+		}
+		rotation, err := controller.GetDeviceKeyRotation(gc.Request.Context(), token, deviceId)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, rotation)
+	}
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/controller"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// postDeviceImport godoc
+// @Summary      Import Devices
+// @Description  Validates a device manifest and creates the platform and chirpstack devices in a background job.
+// @Description  CSV manifests need a header row using the json field names of model.DeviceImportRow.
+// @Description  If any row is invalid, nothing is created and the rejected job is returned with status code 400.
+// @Accept       json
+// @Accept       text/csv
+// @Param        manifest body []model.DeviceImportRow true "device manifest"
+// @Success      202 {object} model.DeviceImportJob "started import job"
+// @Failure      400 {object} model.DeviceImportJob "rejected import job"
+// @Failure      500
+// @Tags         Devices
+// @Security     Bearer
+// @Router       /devices/import [POST]
+func postDeviceImport(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/devices/import", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		rows, err := unmarshalDeviceImportRows(gc)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse request body"), err))
+			return
+		}
+		job, err := controller.ImportDevices(gc.Request.Context(), token, rows)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		if job.Status == model.DeviceImportJobStatusRejected {
+			gc.JSON(http.StatusBadRequest, job)
+			return
+		}
+		gc.JSON(http.StatusAccepted, job)
+	}
+}
+
+// getDeviceImportJob godoc
+// @Summary      Device Import Job
+// @Description  Returns the state and per-row results of an import job
+// @Param        job_id path string true "Job ID"
+// @Success      200 {object} model.DeviceImportJob "import job"
+// @Failure      400
+// @Failure      404
+// @Failure      500
+// @Tags         Devices
+// @Security     Bearer
+// @Router       /devices/import/{job_id} [GET]
+func getDeviceImportJob(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/devices/import/:job_id", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		job, err := controller.GetDeviceImportJob(gc.Request.Context(), token, gc.Param("job_id"))
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, job)
+	}
+}
+
+func unmarshalDeviceImportRows(gc *gin.Context) (rows []model.DeviceImportRow, err error) {
+	defer gc.Request.Body.Close()
+	switch gc.ContentType() {
+	case "application/json":
+		err = json.NewDecoder(gc.Request.Body).Decode(&rows)
+		return rows, err
+	case "text/csv":
+		return unmarshalDeviceImportCsv(gc.Request.Body)
+	default:
+		return nil, fmt.Errorf("unsupported content type %s", gc.ContentType())
+	}
+}
+
+func unmarshalDeviceImportCsv(r io.Reader) (rows []model.DeviceImportRow, err error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("unable to read csv header"), err)
+	}
+	fields := make([]func(row *model.DeviceImportRow) *string, len(header))
+	for i, column := range header {
+		switch strings.ToLower(strings.TrimSpace(column)) {
+		case "dev_eui":
+			fields[i] = func(row *model.DeviceImportRow) *string { return &row.DevEui }
+		case "join_eui":
+			fields[i] = func(row *model.DeviceImportRow) *string { return &row.JoinEui }
+		case "app_key":
+			fields[i] = func(row *model.DeviceImportRow) *string { return &row.AppKey }
+		case "nwk_key":
+			fields[i] = func(row *model.DeviceImportRow) *string { return &row.NwkKey }
+		case "dev_addr":
+			fields[i] = func(row *model.DeviceImportRow) *string { return &row.DevAddr }
+		case "app_s_key":
+			fields[i] = func(row *model.DeviceImportRow) *string { return &row.AppSKey }
+		case "nwk_s_enc_key":
+			fields[i] = func(row *model.DeviceImportRow) *string { return &row.NwkSEncKey }
+		case "s_nwk_s_int_key":
+			fields[i] = func(row *model.DeviceImportRow) *string { return &row.SNwkSIntKey }
+		case "f_nwk_s_int_key":
+			fields[i] = func(row *model.DeviceImportRow) *string { return &row.FNwkSIntKey }
+		case "device_profile_id":
+			fields[i] = func(row *model.DeviceImportRow) *string { return &row.DeviceProfileId }
+		case "name":
+			fields[i] = func(row *model.DeviceImportRow) *string { return &row.Name }
+		default:
+			return nil, fmt.Errorf("unknown csv column %s", column)
+		}
+	}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := model.DeviceImportRow{}
+		for i, value := range record {
+			*fields[i](&row) = strings.TrimSpace(value)
+		}
+		rows = append(rows, row)
+	}
+}
@@ -21,16 +21,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strconv"
 
-	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 
-	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
-
 	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/controller"
 	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
-	"github.com/chirpstack/chirpstack/api/go/v4/gw"
 	"github.com/chirpstack/chirpstack/api/go/v4/integration"
 	"github.com/gin-gonic/gin"
 )
@@ -65,13 +60,7 @@ func postEvent(controller *controller.Controller) (string, string, gin.HandlerFu
 				gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse request body"), err))
 				return
 			}
-			deviceInfo := up.GetDeviceInfo()
-			if deviceInfo == nil {
-				gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse request body"), fmt.Errorf("deviceInfo is nil")))
-				return
-			}
-			log.Logger.Debug("Uplink received", "dev_eui", deviceInfo.DevEui, "payload", fmt.Sprintf("%#v", up.Object), "user", userId, "fport", strconv.FormatUint(uint64(up.FPort), 10))
-			err = controller.HandleEvent(gc.Request.Context(), userId, deviceInfo.DevEui, strconv.FormatUint(uint64(up.FPort), 10), up.Object, up.Time.AsTime(), up.RxInfo, up.DeviceInfo.DeviceProfileId)
+			err = controller.ReceiveUplinkEvent(gc.Request.Context(), userId, &up)
 			if err != nil {
 				gc.Error(err)
 				return
@@ -85,13 +74,7 @@ func postEvent(controller *controller.Controller) (string, string, gin.HandlerFu
 				gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse request body"), err))
 				return
 			}
-			deviceInfo := join.GetDeviceInfo()
-			if deviceInfo == nil {
-				gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse request body"), fmt.Errorf("deviceInfo is nil")))
-				return
-			}
-			log.Logger.Debug("Device joined", "dev_eui", deviceInfo.DevEui, "dev_addr", join.DevAddr, "user", userId)
-			err = controller.AnnotateDeviceJoined(gc.Request.Context(), userId, deviceInfo.DevEui)
+			err = controller.ReceiveJoinEvent(gc.Request.Context(), userId, &join)
 			if err != nil {
 				gc.Error(err)
 				return
@@ -104,18 +87,7 @@ func postEvent(controller *controller.Controller) (string, string, gin.HandlerFu
 				gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse request body"), err))
 				return
 			}
-			deviceInfo := status.GetDeviceInfo()
-			if deviceInfo == nil {
-				gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse request body"), fmt.Errorf("statusInfo is nil")))
-				return
-			}
-			log.Logger.Debug("Status received", "dev_eui", deviceInfo.DevEui, "user", userId)
-			data := map[string]any{
-				"external_power_source":     status.ExternalPowerSource,
-				"battery_level_unavailable": status.BatteryLevelUnavailable,
-				"battery_level":             status.BatteryLevel,
-			}
-			err = controller.HandleEvent(gc.Request.Context(), userId, deviceInfo.DevEui, "status", data, status.Time.AsTime(), []*gw.UplinkRxInfo{}, status.DeviceInfo.DeviceProfileId)
+			err = controller.ReceiveStatusEvent(gc.Request.Context(), userId, &status)
 			if err != nil {
 				gc.Error(err)
 				return
@@ -123,7 +95,7 @@ func postEvent(controller *controller.Controller) (string, string, gin.HandlerFu
 			return
 
 		default:
-			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unknown event type "+event)))
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unknown event type %s", event)))
 			return
 		}
 	}
@@ -139,10 +111,10 @@ func unmarshalEvent(gc *gin.Context, v proto.Message) error {
 	case "application/x-protobuf":
 		fallthrough
 	case "application/octet-stream":
-		return proto.Unmarshal(body, v)
+		return controller.UnmarshalEvent(model.EventEncodingProtobuf, body, v)
 	case "application/json":
-		return protojson.Unmarshal(body, v)
+		return controller.UnmarshalEvent(model.EventEncodingJson, body, v)
 	default:
-		return fmt.Errorf("unsupported content type " + gc.ContentType())
+		return fmt.Errorf("unsupported content type %s", gc.ContentType())
 	}
 }
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/controller"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// postSecretRotation godoc
+// @Summary      Rotate Master Key
+// @Description  Re-encrypts all secrets with the first configured master key. Older master keys may be removed from the configuration afterwards. Requires admin privileges.
+// @Success      200 {object} model.SecretRotation "number of rotated secrets"
+// @Failure      400
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Tags         Secrets
+// @Security     Bearer
+// @Router       /secrets/rotate [POST]
+func postSecretRotation(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/secrets/rotate", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		rotation, err := controller.RotateSecretMasterKey(gc.Request.Context(), token)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, rotation)
+	}
+}
+
+// getSecretAudit godoc
+// @Summary      Secret Audit Trail
+// @Description  Lists accesses to the secret store, newest first. Requires admin privileges.
+// @Param        limit query int false "limit, default 100"
+// @Param        offset query int false "offset, default 0"
+// @Success      200 {array} model.SecretAuditEntry "audit entries"
+// @Failure      400
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Tags         Secrets
+// @Security     Bearer
+// @Router       /secrets/audit [GET]
+func getSecretAudit(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/secrets/audit", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		limit, err := strconv.ParseInt(gc.DefaultQuery("limit", "100"), 10, 64)
+		if err != nil || limit < 1 {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("invalid query param limit"), err))
+			return
+		}
+		offset, err := strconv.ParseInt(gc.DefaultQuery("offset", "0"), 10, 64)
+		if err != nil || offset < 0 {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("invalid query param offset"), err))
+			return
+		}
+		entries, err := controller.ListSecretAudit(gc.Request.Context(), token, limit, offset)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, entries)
+	}
+}
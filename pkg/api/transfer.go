@@ -0,0 +1,133 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/controller"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// postDeviceTransfer godoc
+// @Summary      Transfer Device
+// @Description  Moves a chirpstack device flagged as duplicate into the application of the platform device owner, keeping keys and activation. Requires admin privileges.
+// @Param        device_id path string true "Device ID"
+// @Success      200
+// @Failure      400
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Tags         Transfers
+// @Security     Bearer
+// @Router       /devices/{device_id}/transfer [POST]
+func postDeviceTransfer(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/devices/:device_id/transfer", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		deviceId := gc.Param("device_id")
+		if deviceId == "" {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("missing path param device_id")))
+			return
+		}
+		err = controller.TransferDevice(gc.Request.Context(), token, deviceId)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.Status(http.StatusOK)
+	}
+}
+
+// postGatewayTransfer godoc
+// @Summary      Transfer Gateway
+// @Description  Moves a chirpstack gateway flagged as duplicate into the tenant of the hub owner. Requires admin privileges.
+// @Param        hub_id path string true "Hub ID"
+// @Success      200
+// @Failure      400
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Tags         Transfers
+// @Security     Bearer
+// @Router       /gateways/{hub_id}/transfer [POST]
+func postGatewayTransfer(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/gateways/:hub_id/transfer", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		hubId := gc.Param("hub_id")
+		if hubId == "" {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("missing path param hub_id")))
+			return
+		}
+		err = controller.TransferGateway(gc.Request.Context(), token, hubId)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.Status(http.StatusOK)
+	}
+}
+
+// getTransfers godoc
+// @Summary      List Transfers
+// @Description  Lists recorded device and gateway transfers, newest first. Requires admin privileges.
+// @Param        limit query int false "limit, default 100"
+// @Param        offset query int false "offset, default 0"
+// @Success      200 {array} model.Transfer "transfers"
+// @Failure      400
+// @Failure      403
+// @Failure      500
+// @Tags         Transfers
+// @Security     Bearer
+// @Router       /transfers [GET]
+func getTransfers(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/transfers", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		limit, err := strconv.ParseInt(gc.DefaultQuery("limit", "100"), 10, 64)
+		if err != nil || limit < 1 {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("invalid query param limit"), err))
+			return
+		}
+		offset, err := strconv.ParseInt(gc.DefaultQuery("offset", "0"), 10, 64)
+		if err != nil || offset < 0 {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("invalid query param offset"), err))
+			return
+		}
+		transfers, err := controller.ListTransfers(gc.Request.Context(), token, limit, offset)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, transfers)
+	}
+}
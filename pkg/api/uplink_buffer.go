@@ -0,0 +1,95 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/controller"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// getUplinkDeadLetters godoc
+// @Summary      List Dead Letters
+// @Description  Lists buffered uplinks, which could not be handled after all retries, oldest first. Requires admin privileges.
+// @Param        limit query int false "limit, default 100"
+// @Param        offset query int false "offset, default 0"
+// @Success      200 {array} model.DeadLetter "dead letters"
+// @Failure      400
+// @Failure      403
+// @Failure      500
+// @Tags         Uplinks
+// @Security     Bearer
+// @Router       /uplinks/dead-letters [GET]
+func getUplinkDeadLetters(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/uplinks/dead-letters", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		limit, err := strconv.ParseInt(gc.DefaultQuery("limit", "100"), 10, 64)
+		if err != nil || limit < 1 {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("invalid query param limit"), err))
+			return
+		}
+		offset, err := strconv.ParseInt(gc.DefaultQuery("offset", "0"), 10, 64)
+		if err != nil || offset < 0 {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("invalid query param offset"), err))
+			return
+		}
+		deadLetters, err := controller.ListUplinkDeadLetters(gc.Request.Context(), token, limit, offset)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, deadLetters)
+	}
+}
+
+// postUplinkDeadLetterReplay godoc
+// @Summary      Replay Dead Letters
+// @Description  Moves dead letters back to the uplink buffer. All dead letters are replayed, if no id is given. Requires admin privileges.
+// @Param        id query []string false "dead letter ids" collectionFormat(multi)
+// @Success      200 {object} model.DeadLetterReplay "number of replayed dead letters"
+// @Failure      400
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Tags         Uplinks
+// @Security     Bearer
+// @Router       /uplinks/dead-letters/replay [POST]
+func postUplinkDeadLetterReplay(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/uplinks/dead-letters/replay", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		replay, err := controller.ReplayUplinkDeadLetters(gc.Request.Context(), token, gc.QueryArray("id"))
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, replay)
+	}
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/controller"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// getWebhooks godoc
+// @Summary      List Webhooks
+// @Description  Lists the webhook subscriptions of the requesting user. Secrets are not returned.
+// @Success      200 {array} model.WebhookSubscription "webhooks"
+// @Failure      400
+// @Failure      500
+// @Tags         Webhooks
+// @Security     Bearer
+// @Router       /webhooks [GET]
+func getWebhooks(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/webhooks", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		webhooks, err := controller.ListWebhooks(gc.Request.Context(), token)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, webhooks)
+	}
+}
+
+// postWebhook godoc
+// @Summary      Create Webhook
+// @Description  Subscribes a url to the normalized uplink, join and status events of all devices of the requesting user. Deliveries are signed in the X-Webhook-Signature header "sha256=<hex encoded hmac-sha256 of X-Webhook-Timestamp + '.' + body>". The secret is generated if empty and only returned once.
+// @Accept       json
+// @Param        webhook body model.WebhookSubscription true "url, optional secret and filters"
+// @Success      200 {object} model.WebhookSubscription "created webhook including secret"
+// @Failure      400
+// @Failure      409 "webhooks of the user have been changed concurrently"
+// @Failure      500
+// @Tags         Webhooks
+// @Security     Bearer
+// @Router       /webhooks [POST]
+func postWebhook(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/webhooks", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		var webhook model.WebhookSubscription
+		err = gc.ShouldBindJSON(&webhook)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse request body"), err))
+			return
+		}
+		webhook, err = controller.CreateWebhook(gc.Request.Context(), token, webhook)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, webhook)
+	}
+}
+
+// putWebhook godoc
+// @Summary      Update Webhook
+// @Description  Replaces url and filters of a webhook subscription. The secret is kept, unless a new one is given.
+// @Accept       json
+// @Param        id path string true "Webhook ID"
+// @Param        webhook body model.WebhookSubscription true "url, optional secret and filters"
+// @Success      200 {object} model.WebhookSubscription "updated webhook"
+// @Failure      400
+// @Failure      404
+// @Failure      500
+// @Tags         Webhooks
+// @Security     Bearer
+// @Router       /webhooks/{id} [PUT]
+func putWebhook(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodPut, "/webhooks/:id", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		var webhook model.WebhookSubscription
+		err = gc.ShouldBindJSON(&webhook)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse request body"), err))
+			return
+		}
+		webhook, err = controller.UpdateWebhook(gc.Request.Context(), token, gc.Param("id"), webhook)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, webhook)
+	}
+}
+
+// deleteWebhook godoc
+// @Summary      Delete Webhook
+// @Description  Removes a webhook subscription and its delivery log.
+// @Param        id path string true "Webhook ID"
+// @Success      200
+// @Failure      400
+// @Failure      404
+// @Failure      500
+// @Tags         Webhooks
+// @Security     Bearer
+// @Router       /webhooks/{id} [DELETE]
+func deleteWebhook(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodDelete, "/webhooks/:id", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		err = controller.DeleteWebhook(gc.Request.Context(), token, gc.Param("id"))
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.Status(http.StatusOK)
+	}
+}
+
+// getWebhookDeliveries godoc
+// @Summary      List Webhook Deliveries
+// @Description  Lists the latest delivery attempts of a webhook subscription, newest first.
+// @Param        id path string true "Webhook ID"
+// @Param        limit query int false "limit, default 100"
+// @Param        offset query int false "offset, default 0"
+// @Success      200 {array} model.WebhookDelivery "delivery attempts"
+// @Failure      400
+// @Failure      404
+// @Failure      500
+// @Tags         Webhooks
+// @Security     Bearer
+// @Router       /webhooks/{id}/deliveries [GET]
+func getWebhookDeliveries(controller *controller.Controller) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/webhooks/:id/deliveries", func(gc *gin.Context) {
+		token, err := jwt.GetParsedToken(gc.Request)
+		if err != nil {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("unable to parse token"), err))
+			return
+		}
+		limit, err := strconv.ParseInt(gc.DefaultQuery("limit", "100"), 10, 64)
+		if err != nil || limit < 1 {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("invalid query param limit"), err))
+			return
+		}
+		offset, err := strconv.ParseInt(gc.DefaultQuery("offset", "0"), 10, 64)
+		if err != nil || offset < 0 {
+			gc.Error(errors.Join(model.ErrBadRequest, fmt.Errorf("invalid query param offset"), err))
+			return
+		}
+		deliveries, err := controller.ListWebhookDeliveries(gc.Request.Context(), token, gc.Param("id"), limit, offset)
+		if err != nil {
+			gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, deliveries)
+	}
+}
@@ -16,6 +16,8 @@
 
 package configuration
 
+import "time"
+
 type Config struct {
 	ChirpstackUrl            string          `env_var:"CHIRPSTACK_URL"`
 	ChirpstackApiToken       ChirpstackToken `env_var:"CHIRPSTACK_API_TOKEN"`
@@ -23,12 +25,14 @@ type Config struct {
 	KeycloakUrl              string          `env_var:"KEYCLOAK_URL"`
 	KeycloakClientId         string          `env_var:"KEYCLOAK_CLIENT_ID"`
 	KeycloakClientSecret     string          `env_var:"KEYCLOAK_CLIENT_SECRET"`
+	KeycloakRealm            string          `env_var:"KEYCLOAK_REALM"`
 	LogLevel                 string          `env_var:"LOG_LEVEL"`
 	LogHandler               string          `env_var:"LOG_HANDLER"`
 	ServerPort               uint            `env_var:"SERVER_PORT"`
 	ServerPortCommands       uint            `env_var:"SERVER_PORT_COMMANDS"`
 	Host                     string          `env_var:"HOST"`
 	KafkaBootstrap           string          `env_var:"KAFKA_BOOTSTRAP"`
+	KafkaUserTopic           string          `env_var:"KAFKA_USER_TOPIC"` // topic of platform user events, empty to disable
 	DeviceRepoUrl            string          `env_var:"DEVICE_REPO_URL"`
 	PermissionsV2Url         string          `env_var:"PERMISSIONS_V2_URL"`
 	MemcachedUrl             string          `env_var:"MEMCACHED_URL"`
@@ -43,4 +47,37 @@ type Config struct {
 	RedisUrl                 string          `env_var:"REDIS_URL"`
 	DisableSync              bool            `env_var:"DISABLE_SYNC"`
 	UpdateTenants            bool            `env_var:"UPDATE_TENANTS"`
+	TagAttributePrefix       string          `env_var:"TAG_ATTRIBUTE_PREFIX"`        // attributes with this prefix are synced to chirpstack device and gateway tags, empty to disable
+	VariableAttributePrefix  string          `env_var:"VARIABLE_ATTRIBUTE_PREFIX"`   // attributes with this prefix are synced to chirpstack device variables, empty to disable
+	CommandCatalogFile       string          `env_var:"COMMAND_CATALOG_FILE"`        // yaml or json file with downlink commands per device profile, see model.CommandCatalog
+	AnnotationRulesFile      string          `env_var:"ANNOTATION_RULES_FILE"`       // yaml or json list of rules assigning semantic ids to inferred content variables, see model.AnnotationRule
+	DeviceTypeUpdateDebounce time.Duration   `env_var:"DEVICE_TYPE_UPDATE_DEBOUNCE"` // delay to merge device type changes inferred from uplinks before writing them
+	UplinkBuffer             bool            `env_var:"UPLINK_BUFFER"`               // persist uplinks to a redis stream and acknowledge them to chirpstack before they are handled
+	UplinkBufferWorkers      uint            `env_var:"UPLINK_BUFFER_WORKERS"`
+	UplinkBufferMaxAttempts  uint            `env_var:"UPLINK_BUFFER_MAX_ATTEMPTS"` // buffered uplinks are moved to the dead letter stream after this many failed attempts
+	UplinkBufferMaxBackoff   time.Duration   `env_var:"UPLINK_BUFFER_MAX_BACKOFF"`
+	UplinkBufferDeadLetters  int64           `env_var:"UPLINK_BUFFER_DEAD_LETTERS"` // the dead letter stream is trimmed to about this many uplinks
+	DeduplicationTtl         time.Duration   `env_var:"DEDUPLICATION_TTL"`          // how long processed chirpstack deduplication ids are remembered, 0 to disable deduplication
+	IngestionMode            string          `env_var:"INGESTION_MODE"`             // "http", "redis" or "mqtt", see model.IngestionModeHttp, model.IngestionModeRedis and model.IngestionModeMqtt
+	ChirpstackEventStream    string          `env_var:"CHIRPSTACK_EVENT_STREAM"`    // redis stream of chirpstack device events, read in ingestion mode "redis"
+	MqttBroker               string          `env_var:"MQTT_BROKER"`                // broker of the chirpstack mqtt integration, e.g. tcp://mosquitto:1883
+	MqttUsername             string          `env_var:"MQTT_USERNAME"`
+	MqttPassword             string          `env_var:"MQTT_PASSWORD"`
+	MqttClientId             string          `env_var:"MQTT_CLIENT_ID"`                 // defaults to a client id derived from the hostname
+	MqttEventTopic           string          `env_var:"MQTT_EVENT_TOPIC"`               // subscribed in ingestion mode "mqtt", use a shared subscription for multiple instances
+	MqttEncoding             string          `env_var:"MQTT_ENCODING"`                  // "json" or "protobuf", has to match the json setting of the chirpstack mqtt integration
+	MqttDownlinks            bool            `env_var:"MQTT_DOWNLINKS"`                 // publish downlinks to the mqtt integration instead of enqueuing them with the chirpstack api
+	EventSink                string          `env_var:"EVENT_SINK"`                     // "connector", "file" or "webhook", defaults to "connector" with kafka and "file" otherwise
+	EventSinkFile            string          `env_var:"EVENT_SINK_FILE"`                // json lines file of the file sink, stdout if empty
+	EventSinkUrl             string          `env_var:"EVENT_SINK_URL"`                 // url of the webhook sink
+	WebhookWorkers           uint            `env_var:"WEBHOOK_WORKERS"`                // workers delivering events to user webhooks, 0 to disable webhooks
+	WebhookMaxAttempts       uint            `env_var:"WEBHOOK_MAX_ATTEMPTS"`           // failed deliveries are retried with exponential backoff up to this many attempts
+	WebhookAllowPrivateNet   bool            `env_var:"WEBHOOK_ALLOW_PRIVATE_NETWORKS"` // allow webhooks to loopback, private and link local addresses
+	ChirpstackAdoption       bool            `env_var:"CHIRPSTACK_ADOPTION"`            // create platform devices and hubs for unknown chirpstack devices and gateways instead of deleting them
+	DeviceKeysWriteOnly      bool            `env_var:"DEVICE_KEYS_WRITE_ONLY"`         // replace key attributes with fingerprints after they have been pushed to chirpstack, with SECRET_STORE_BACKEND with references to the stored keys
+	SecretStoreBackend       string          `env_var:"SECRET_STORE_BACKEND"`           // "redis", "file" or empty to disable the secret store
+	SecretStoreFile          string          `env_var:"SECRET_STORE_FILE"`
+	SecretStoreMasterKeys    []string        `env_var:"SECRET_STORE_MASTER_KEYS"` // list of <id>:<base64 encoded 32 byte key>, the first key encrypts new secrets
+	UserDeletionMaxShrink    uint            `env_var:"USER_DELETION_MAX_SHRINK"` // percentage the keycloak user count may shrink between runs before deletions are refused
+	TenantPlans              TenantPlans     `env_var:"TENANT_PLANS"`             // json list of plans mapping keycloak roles, groups and attributes to tenant settings and quotas
 }
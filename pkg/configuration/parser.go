@@ -18,6 +18,7 @@ package configuration
 
 import (
 	"reflect"
+	"time"
 
 	envldr "github.com/SENERGY-Platform/go-env-loader"
 )
@@ -25,9 +26,15 @@ import (
 func GetTypeParser() map[reflect.Type]envldr.Parser {
 	return map[reflect.Type]envldr.Parser{
 		reflect.TypeFor[ChirpstackToken](): chirpstackTokenParser,
+		reflect.TypeFor[TenantPlans]():     tenantPlansParser,
+		reflect.TypeFor[time.Duration]():   durationParser,
 	}
 }
 
 func chirpstackTokenParser(_ reflect.Type, val string, _ []string, _ map[string]string) (interface{}, error) {
 	return ChirpstackToken(val), nil
 }
+
+func durationParser(_ reflect.Type, val string, _ []string, _ map[string]string) (interface{}, error) {
+	return time.ParseDuration(val)
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package configuration
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+)
+
+// TenantPlans are matched in order against the keycloak realm roles, groups and attributes of a user. The first matching plan is applied.
+type TenantPlans []TenantPlan
+
+type TenantPlan struct {
+	Name       string            `json:"name"`
+	Roles      []string          `json:"roles,omitempty"`      // realm roles, including composite roles. Any role matches
+	Groups     []string          `json:"groups,omitempty"`     // group names or paths. Any group matches
+	Attributes map[string]string `json:"attributes,omitempty"` // user attributes. All attributes need to match
+
+	// tenant user flags of the owner in their own tenant
+	IsAdmin        bool `json:"is_admin"`
+	IsDeviceAdmin  bool `json:"is_device_admin"`
+	IsGatewayAdmin bool `json:"is_gateway_admin"`
+
+	// tenant settings
+	CanHaveGateways     bool   `json:"can_have_gateways"`
+	PrivateGatewaysUp   bool   `json:"private_gateways_up"`
+	PrivateGatewaysDown bool   `json:"private_gateways_down"`
+	MaxGatewayCount     uint32 `json:"max_gateway_count"` // 0 for unlimited
+	MaxDeviceCount      uint32 `json:"max_device_count"`  // 0 for unlimited
+}
+
+// DefaultTenantPlan is used if no configured plan matches.
+var DefaultTenantPlan = TenantPlan{
+	Name:                "default",
+	CanHaveGateways:     true,
+	PrivateGatewaysUp:   true,
+	PrivateGatewaysDown: true,
+}
+
+// Matches returns true if the plan has no conditions or if all configured kinds of conditions are met.
+func (p TenantPlan) Matches(roles []string, groups []string, attributes map[string][]string) bool {
+	if len(p.Roles) > 0 && !slices.ContainsFunc(p.Roles, func(role string) bool { return slices.Contains(roles, role) }) {
+		return false
+	}
+	if len(p.Groups) > 0 && !slices.ContainsFunc(p.Groups, func(group string) bool { return slices.Contains(groups, group) }) {
+		return false
+	}
+	for key, value := range p.Attributes {
+		if !slices.Contains(attributes[key], value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Select returns the first matching plan or DefaultTenantPlan.
+func (p TenantPlans) Select(roles []string, groups []string, attributes map[string][]string) TenantPlan {
+	for _, plan := range p {
+		if plan.Matches(roles, groups, attributes) {
+			return plan
+		}
+	}
+	return DefaultTenantPlan
+}
+
+func tenantPlansParser(_ reflect.Type, val string, _ []string, _ map[string]string) (interface{}, error) {
+	plans := TenantPlans{}
+	err := json.Unmarshal([]byte(val), &plans)
+	return plans, err
+}
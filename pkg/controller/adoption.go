@@ -0,0 +1,152 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/chirpstack/chirpstack/api/go/v4/api"
+)
+
+// adoptChirpDevice creates the platform device for a chirpstack device, which is unknown to the platform.
+// The device type is resolved from the device profile. Keys and sessions remain in chirpstack.
+// Description, tags and variables are copied to attributes, so the next sync does not remove them from chirpstack.
+func (c *Controller) adoptChirpDevice(ctx context.Context, ownerId string, item *api.DeviceListItem) error {
+	deviceType, err := c.getDeviceTypeOfDeviceProfile(item.DeviceProfileId)
+	if err != nil {
+		return err
+	}
+	if deviceType == nil {
+		return fmt.Errorf("no device type for device profile %s, profile might not be synced yet", item.DeviceProfileId)
+	}
+	chirpDevice, err := c.chirpDevice.Get(ctx, &api.GetDeviceRequest{DevEui: item.DevEui})
+	if err != nil {
+		return err
+	}
+
+	name := item.Name
+	if name == "" {
+		name = item.DevEui
+	}
+	device := models.Device{
+		LocalId:      strings.ToLower(item.DevEui),
+		Name:         name,
+		DeviceTypeId: deviceType.Id,
+		OwnerId:      ownerId,
+		Attributes: []models.Attribute{{
+			Key:    model.DeviceAttributeAdoptedAtKey,
+			Value:  time.Now().Format(time.RFC3339),
+			Origin: model.AttributeOrigin,
+		}},
+	}
+	if chirpDevice.Device.JoinEui != "" {
+		device.Attributes = append(device.Attributes, models.Attribute{
+			Key:    model.DeviceAttributeJoinEuiKey,
+			Value:  strings.ToLower(chirpDevice.Device.JoinEui),
+			Origin: model.AttributeOrigin,
+		})
+	}
+	values := c.chirpstackAttributeValues(chirpDevice.Device.Description, chirpDevice.Device.Tags, chirpDevice.Device.Variables)
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		device.Attributes = append(device.Attributes, models.Attribute{Key: key, Value: values[key], Origin: model.AttributeOriginChirpstack})
+	}
+	device, err = c.createPlatformDevice(ownerId, device)
+	if err != nil {
+		return errors.Join(fmt.Errorf("unable to create platform device"), err)
+	}
+	log.Logger.Info("adopted chirpstack device", "dev_eui", item.DevEui, "device_id", device.Id, "owner_id", ownerId)
+	return nil
+}
+
+// adoptChirpGateway creates the platform hub for a chirpstack gateway in a managed tenant, which is unknown to the platform.
+func (c *Controller) adoptChirpGateway(ctx context.Context, ownerId string, item *api.GatewayListItem) error {
+	name := item.Name
+	if name == "" {
+		name = item.GatewayId
+	}
+	hub := models.Hub{
+		Name:           name,
+		OwnerId:        ownerId,
+		DeviceLocalIds: []string{},
+		DeviceIds:      []string{},
+		Attributes: []models.Attribute{{
+			Key:    model.GatewayAttributeEUI,
+			Value:  item.GatewayId,
+			Origin: model.AttributeOrigin,
+		}, {
+			Key:    model.GatewayAttributeAdoptedAt,
+			Value:  time.Now().Format(time.RFC3339),
+			Origin: model.AttributeOrigin,
+		}},
+	}
+	if item.Location != nil && item.Location.Latitude != 0 && item.Location.Longitude != 0 {
+		hub.Attributes = append(hub.Attributes, models.Attribute{
+			Key:    model.GatewayAttributeLat,
+			Value:  strconv.FormatFloat(item.Location.Latitude, 'f', -1, 64),
+			Origin: model.AttributeOriginWebUI,
+		}, models.Attribute{
+			Key:    model.GatewayAttributeLon,
+			Value:  strconv.FormatFloat(item.Location.Longitude, 'f', -1, 64),
+			Origin: model.AttributeOriginWebUI,
+		})
+	}
+	token, err := c.userToken(ownerId)
+	if err != nil {
+		return err
+	}
+	hub, err, _ = c.deviceRepo.SetHub(token, hub)
+	if err != nil {
+		return errors.Join(fmt.Errorf("unable to create platform hub"), err)
+	}
+	log.Logger.Info("adopted chirpstack gateway", "gateway_eui", item.GatewayId, "hub_id", hub.Id, "owner_id", ownerId)
+	return nil
+}
+
+// adoptOrKeepChirpGateway adopts a gateway of a managed tenant. Gateways, which are known to the platform under a different owner
+// or which belong to unmanaged tenants, are kept as they are.
+func (c *Controller) adoptOrKeepChirpGateway(ctx context.Context, gw *api.GatewayListItem, hubs []models.Hub) {
+	if slices.ContainsFunc(hubs, func(hub models.Hub) bool {
+		return getAttributeValue(hub.Attributes, model.GatewayAttributeEUI) == gw.GatewayId
+	}) {
+		log.Logger.Warn("not adopting chirpstack gateway, hub with the same eui exists for a different owner", "gateway_eui", gw.GatewayId)
+		return
+	}
+	ownerId, err := c.getChirpstackTenantOwnerId(ctx, gw.TenantId)
+	if err != nil {
+		log.Logger.Error("unable to read owner of chirpstack tenant", attributes.ErrorKey, err, "tenant_id", gw.TenantId)
+		return
+	}
+	if ownerId == "" {
+		log.Logger.Debug("not adopting chirpstack gateway of unmanaged tenant", "gateway_eui", gw.GatewayId, "tenant_id", gw.TenantId)
+		return
+	}
+	err = c.adoptChirpGateway(ctx, ownerId, gw)
+	if err != nil {
+		log.Logger.Error("unable to adopt chirpstack gateway", attributes.ErrorKey, err, "gateway_eui", gw.GatewayId, "owner_id", ownerId)
+	}
+}
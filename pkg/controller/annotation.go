@@ -0,0 +1,165 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"sigs.k8s.io/yaml"
+)
+
+type annotationRule struct {
+	model.AnnotationRule
+	pattern *regexp.Regexp
+	vendor  *regexp.Regexp // nil matches all vendors
+	model   *regexp.Regexp // nil matches all models
+}
+
+func loadAnnotationRules(path string) (rules []annotationRule, err error) {
+	if path == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b, err = yaml.YAMLToJSON(b) // json is valid yaml
+	if err != nil {
+		return nil, err
+	}
+	configured := []model.AnnotationRule{}
+	err = json.Unmarshal(b, &configured)
+	if err != nil {
+		return nil, err
+	}
+	for i, rule := range configured {
+		compiled, err := compileAnnotationRule(rule)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("invalid annotation rule %d", i), err)
+		}
+		rules = append(rules, compiled)
+	}
+	return rules, nil
+}
+
+func compileAnnotationRule(rule model.AnnotationRule) (compiled annotationRule, err error) {
+	compiled.AnnotationRule = rule
+	if rule.Pattern == "" {
+		return compiled, fmt.Errorf("missing pattern")
+	}
+	if rule.CharacteristicId == "" && rule.FunctionId == "" && rule.AspectId == "" && rule.UnitReference == "" {
+		return compiled, fmt.Errorf("rule %s assigns nothing", rule.Pattern)
+	}
+	compiled.pattern, err = regexp.Compile(rule.Pattern)
+	if err != nil {
+		return compiled, err
+	}
+	if rule.Vendor != "" {
+		compiled.vendor, err = regexp.Compile(rule.Vendor)
+		if err != nil {
+			return compiled, err
+		}
+	}
+	if rule.Model != "" {
+		compiled.model, err = regexp.Compile(rule.Model)
+		if err != nil {
+			return compiled, err
+		}
+	}
+	return compiled, nil
+}
+
+// annotationRulesOf returns the rules, which are in scope of the vendor and model of the device type.
+func (c *Controller) annotationRulesOf(dt models.DeviceType) []annotationRule {
+	if len(c.annotationRules) == 0 {
+		return nil
+	}
+	vendor := ""
+	deviceModel := ""
+	for _, attr := range dt.Attributes {
+		switch attr.Key {
+		case model.DeviceTypeAttributeVendorKey:
+			vendor = attr.Value
+		case model.DeviceTypeAttributeModelKey:
+			deviceModel = attr.Value
+		}
+	}
+	rules := []annotationRule{}
+	for _, rule := range c.annotationRules {
+		if rule.vendor != nil && !rule.vendor.MatchString(vendor) {
+			continue
+		}
+		if rule.model != nil && !rule.model.MatchString(deviceModel) {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// annotateContentVariable applies the first matching rule to each sub content variable of cv, which has no counterpart in base.
+// Content variables existing in base are never changed, so manual edits are preserved.
+func annotateContentVariable(rules []annotationRule, cv *models.ContentVariable, base *models.ContentVariable, path string) {
+	if len(rules) == 0 {
+		return
+	}
+	for i := range cv.SubContentVariables {
+		sub := &cv.SubContentVariables[i]
+		subPath := sub.Name
+		if path != "" {
+			subPath = path + "." + sub.Name
+		}
+		var baseSub *models.ContentVariable
+		if base != nil {
+			for j := range base.SubContentVariables {
+				if base.SubContentVariables[j].Name == sub.Name {
+					baseSub = &base.SubContentVariables[j]
+					break
+				}
+			}
+		}
+		if baseSub == nil {
+			annotateNewContentVariable(rules, sub, subPath)
+		}
+		annotateContentVariable(rules, sub, baseSub, subPath)
+	}
+}
+
+func annotateNewContentVariable(rules []annotationRule, cv *models.ContentVariable, path string) {
+	if cv.CharacteristicId != "" || cv.FunctionId != "" || cv.AspectId != "" || cv.UnitReference != "" {
+		return
+	}
+	for _, rule := range rules {
+		if rule.Type != "" && rule.Type != cv.Type {
+			continue
+		}
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		cv.CharacteristicId = rule.CharacteristicId
+		cv.FunctionId = rule.FunctionId
+		cv.AspectId = rule.AspectId
+		cv.UnitReference = rule.UnitReference
+		return
+	}
+}
@@ -0,0 +1,292 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	device_repo "github.com/SENERGY-Platform/device-repository/lib/model"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/chirpstack/chirpstack/api/go/v4/api"
+	"github.com/go-redis/redis/v8"
+)
+
+const chirpDeviceDescription = "Managed by lorawan-platform-connector"
+
+// Conflict policy for edits in the chirpstack ui:
+//   - names follow the last edit, regardless of where it happened
+//   - descriptions, tags and variables are kept as attributes. Attributes with origin web-ui were set in the platform and win,
+//     chirpstack edits to them are reverted. All other attributes follow chirpstack and get origin chirpstack.
+
+// syncChirpDeviceEdits propagates name, description, tags and variables of a chirpstack device to its platform device.
+func (c *Controller) syncChirpDeviceEdits(ctx context.Context, devEui string) error {
+	chirpDevice, err := c.chirpDevice.Get(ctx, &api.GetDeviceRequest{DevEui: devEui})
+	if err != nil {
+		return err
+	}
+	tenantId, err := c.getApplicationTenantId(ctx, chirpDevice.Device.ApplicationId)
+	if err != nil {
+		return err
+	}
+	ownerId, err := c.getChirpstackTenantOwnerId(ctx, tenantId)
+	if err != nil {
+		return err
+	}
+	if ownerId == "" {
+		return nil // unmanaged tenant
+	}
+	c.jwtMux.RLock()
+	devices, _, err, _ := c.deviceRepo.ListExtendedDevices("Bearer "+c.jwt.AccessToken, device_repo.ExtendedDeviceListOptions{
+		LocalIds: []string{strings.ToLower(devEui)},
+		Owner:    ownerId,
+		FullDt:   true,
+	})
+	c.jwtMux.RUnlock()
+	if err != nil {
+		return err
+	}
+	if len(devices) == 0 {
+		return nil
+	}
+	device := devices[0]
+
+	updated := false
+	if device.DisplayName != "" && device.DisplayName != chirpDevice.Device.Name {
+		device.DisplayName = chirpDevice.Device.Name
+		updated = true
+	} else if device.DisplayName == "" && device.Name != chirpDevice.Device.Name {
+		device.Name = chirpDevice.Device.Name
+		updated = true
+	}
+	description := chirpDevice.Device.Description
+	if description == chirpDeviceDescription {
+		description = ""
+	}
+	changed, conflict := c.mergeChirpstackAttributes(&device.Attributes, c.chirpstackAttributeValues(description, chirpDevice.Device.Tags, chirpDevice.Device.Variables), true)
+	updated = changed || updated // careful: lazy eval!
+	if updated {
+		err = c.updatePlatformDevice(ownerId, device.Device)
+		if err != nil {
+			return errors.Join(fmt.Errorf("unable to update platform device"), err)
+		}
+		log.Logger.Debug("propagated chirpstack device edits", "dev_eui", devEui, "device_id", device.Id)
+		return nil // the device update event triggers the sync
+	}
+	if conflict {
+		return c.SyncDevice(ctx, &device)
+	}
+	return nil
+}
+
+// syncChirpGatewayEdits propagates name, description, tags, location and stats interval of a chirpstack gateway to its hub.
+func (c *Controller) syncChirpGatewayEdits(ctx context.Context, gatewayId string) error {
+	gateway, err := c.chirpGateway.Get(ctx, &api.GetGatewayRequest{GatewayId: gatewayId})
+	if err != nil {
+		return err
+	}
+	ownerId, err := c.getChirpstackTenantOwnerId(ctx, gateway.Gateway.TenantId)
+	if err != nil {
+		return err
+	}
+	if ownerId == "" {
+		return nil // unmanaged tenant
+	}
+	hubs, err := c.listHubsOfOwnerByEui(ownerId, gatewayId)
+	if err != nil {
+		return err
+	}
+	if len(hubs) == 0 {
+		return nil
+	}
+	hub := hubs[0]
+
+	updated := false
+	if hub.Name != gateway.Gateway.Name {
+		hub.Name = gateway.Gateway.Name
+		updated = true
+	}
+	changed, conflict := c.mergeChirpstackAttributes(&hub.Attributes, c.chirpstackAttributeValues(gateway.Gateway.Description, gateway.Gateway.Tags, nil), false)
+	updated = changed || updated                                  // careful: lazy eval!
+	updated = fillHubAttributes(gateway.Gateway, &hub) || updated // careful: lazy eval!
+	if updated {
+		c.jwtMux.RLock()
+		_, err, _ = c.deviceRepo.SetHub("Bearer "+c.jwt.AccessToken, hub)
+		c.jwtMux.RUnlock()
+		if err != nil {
+			return errors.Join(fmt.Errorf("unable to update hub"), err)
+		}
+		log.Logger.Debug("propagated chirpstack gateway edits", "gateway_eui", gatewayId, "hub_id", hub.Id)
+		return nil // the hub update event triggers the sync
+	}
+	if conflict {
+		return c.SyncGateway(ctx, &hub)
+	}
+	return nil
+}
+
+// chirpstackAttributeValues maps the chirpstack description, tags and variables to attribute keys. Tags and variables are skipped if their prefix is not configured.
+func (c *Controller) chirpstackAttributeValues(description string, tags map[string]string, variables map[string]string) map[string]string {
+	values := map[string]string{}
+	if description != "" {
+		values[model.AttributeDescriptionKey] = description
+	}
+	if c.config.TagAttributePrefix != "" {
+		for key, value := range tags {
+			values[c.config.TagAttributePrefix+key] = value
+		}
+	}
+	if c.config.VariableAttributePrefix != "" {
+		for key, value := range variables {
+			values[c.config.VariableAttributePrefix+key] = value
+		}
+	}
+	return values
+}
+
+// isChirpstackAttribute returns true for attributes, which mirror a chirpstack description, tag or variable.
+func (c *Controller) isChirpstackAttribute(key string, withVariables bool) bool {
+	return key == model.AttributeDescriptionKey ||
+		(c.config.TagAttributePrefix != "" && strings.HasPrefix(key, c.config.TagAttributePrefix)) ||
+		(withVariables && c.config.VariableAttributePrefix != "" && strings.HasPrefix(key, c.config.VariableAttributePrefix))
+}
+
+// mergeChirpstackAttributes applies the chirpstack values to the attributes according to the conflict policy.
+// conflict is true, if chirpstack differs from an attribute set in the platform, which needs to be pushed back to chirpstack.
+func (c *Controller) mergeChirpstackAttributes(attributes *[]models.Attribute, values map[string]string, withVariables bool) (updated bool, conflict bool) {
+	result := []models.Attribute{}
+	for _, a := range *attributes {
+		if !c.isChirpstackAttribute(a.Key, withVariables) {
+			result = append(result, a)
+			continue
+		}
+		value, ok := values[a.Key]
+		delete(values, a.Key)
+		switch {
+		case a.Origin == model.AttributeOriginWebUI:
+			conflict = conflict || !ok || value != a.Value
+			result = append(result, a)
+		case !ok:
+			updated = true
+		default:
+			updated = updated || value != a.Value || a.Origin != model.AttributeOriginChirpstack
+			result = append(result, models.Attribute{Key: a.Key, Value: value, Origin: model.AttributeOriginChirpstack})
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		updated = true
+		result = append(result, models.Attribute{Key: key, Value: values[key], Origin: model.AttributeOriginChirpstack})
+	}
+	*attributes = result
+	return updated, conflict
+}
+
+// chirpstackDeviceFields derives description, tags and variables of a chirpstack device from the device attributes.
+// Tags and variables are reconciled with managedAttributeKeys, the description is kept from existing if no attribute exists.
+func (c *Controller) chirpstackDeviceFields(attributes []models.Attribute, existingDescription string, existingTags map[string]string, existingVariables map[string]string, managed managedKeys) (description string, tags map[string]string, variables map[string]string, newManaged managedKeys) {
+	description = existingDescription
+	for _, a := range attributes {
+		if a.Key == model.AttributeDescriptionKey {
+			description = a.Value
+		}
+	}
+	tags, newManaged.Tags = managedAttributeKeys(attributes, c.config.TagAttributePrefix, existingTags, managed.Tags)
+	variables, newManaged.Variables = managedAttributeKeys(attributes, c.config.VariableAttributePrefix, existingVariables, managed.Variables)
+	return description, tags, variables, newManaged
+}
+
+// managedAttributeKeys applies the attributes with the prefix to a copy of existing. Keys, which were managed before and have no attribute anymore, are removed.
+// Keys, which were not set by the connector, are kept. If the prefix is empty, existing is returned unchanged and no key is managed.
+func managedAttributeKeys(attributes []models.Attribute, prefix string, existing map[string]string, managed []string) (result map[string]string, newManaged []string) {
+	result = map[string]string{}
+	maps.Copy(result, existing)
+	if prefix == "" {
+		return result, nil
+	}
+	desired := map[string]string{}
+	for _, a := range attributes {
+		if key, ok := strings.CutPrefix(a.Key, prefix); ok {
+			desired[key] = a.Value
+		}
+	}
+	for _, key := range managed {
+		if _, ok := desired[key]; !ok {
+			delete(result, key)
+		}
+	}
+	maps.Copy(result, desired)
+	return result, slices.Sorted(maps.Keys(desired))
+}
+
+// managedKeys are the chirpstack tags and variables, which were set from attributes by the connector.
+type managedKeys struct {
+	Tags      []string `json:"tags,omitempty"`
+	Variables []string `json:"variables,omitempty"`
+}
+
+// getManagedKeys reads the keys, which were set from attributes by the last sync of the chirpstack device or gateway.
+func (c *Controller) getManagedKeys(ctx context.Context, kind string, eui string) (result managedKeys, err error) {
+	b, err := c.rdb.Get(ctx, fmt.Sprintf(model.RedisKeyFmtManagedKeys, kind, strings.ToLower(eui))).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(b, &result)
+	return result, err
+}
+
+// deleteManagedKeys removes the keys of a deleted chirpstack device or gateway.
+func (c *Controller) deleteManagedKeys(ctx context.Context, kind string, eui string) error {
+	return c.rdb.Del(ctx, fmt.Sprintf(model.RedisKeyFmtManagedKeys, kind, strings.ToLower(eui))).Err()
+}
+
+// setManagedKeys stores the keys, which were set from attributes, if they differ from previous.
+func (c *Controller) setManagedKeys(ctx context.Context, kind string, eui string, previous managedKeys, keys managedKeys) error {
+	if slices.Equal(previous.Tags, keys.Tags) && slices.Equal(previous.Variables, keys.Variables) {
+		return nil
+	}
+	if len(keys.Tags) == 0 && len(keys.Variables) == 0 {
+		return c.deleteManagedKeys(ctx, kind, eui)
+	}
+	b, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	return c.rdb.Set(ctx, fmt.Sprintf(model.RedisKeyFmtManagedKeys, kind, strings.ToLower(eui)), b, 0).Err()
+}
+
+// chirpstackGatewayFields derives description and tags of a chirpstack gateway from the hub attributes.
+// Tags are reconciled with managedAttributeKeys like device tags, the description is kept from existing if no attribute exists.
+func chirpstackGatewayFields(attributes []models.Attribute, tagPrefix string, existingDescription string, existingTags map[string]string, managedTags []string) (description string, tags map[string]string, newManagedTags []string) {
+	description = existingDescription
+	for _, a := range attributes {
+		if a.Key == model.AttributeDescriptionKey {
+			description = a.Value
+		}
+	}
+	tags, newManagedTags = managedAttributeKeys(attributes, tagPrefix, existingTags, managedTags)
+	return description, tags, newManagedTags
+}
@@ -0,0 +1,255 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/cache"
+	"github.com/chirpstack/chirpstack/api/go/v4/gw"
+	"github.com/chirpstack/chirpstack/api/go/v4/integration"
+	"github.com/go-redis/redis/v8"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const chirpstackEventGroup = "lorawan-platform-connector"
+
+// entries pending longer than chirpstackEventClaimIdle were left by a crashed consumer and are claimed every chirpstackEventClaimInterval
+const chirpstackEventClaimIdle = 5 * time.Minute
+const chirpstackEventClaimInterval = time.Minute
+
+func (c *Controller) ReceiveUplinkEvent(ctx context.Context, userId string, up *integration.UplinkEvent) error {
+	deviceInfo := up.GetDeviceInfo()
+	if deviceInfo == nil {
+		return errors.Join(model.ErrBadRequest, fmt.Errorf("deviceInfo is nil"))
+	}
+	log.Logger.Debug("Uplink received", "dev_eui", deviceInfo.DevEui, "payload", fmt.Sprintf("%#v", up.Object), "user", userId, "fport", strconv.FormatUint(uint64(up.FPort), 10))
+	return c.ReceiveEvent(ctx, userId, deviceInfo.DevEui, strconv.FormatUint(uint64(up.FPort), 10), up.Object, up.Time.AsTime(), up.RxInfo, deviceInfo.DeviceProfileId, up.DeduplicationId)
+}
+
+func (c *Controller) ReceiveJoinEvent(ctx context.Context, userId string, join *integration.JoinEvent) error {
+	deviceInfo := join.GetDeviceInfo()
+	if deviceInfo == nil {
+		return errors.Join(model.ErrBadRequest, fmt.Errorf("deviceInfo is nil"))
+	}
+	log.Logger.Debug("Device joined", "dev_eui", deviceInfo.DevEui, "dev_addr", join.DevAddr, "user", userId)
+	return c.AnnotateDeviceJoined(ctx, userId, deviceInfo.DevEui, join.Time.AsTime())
+}
+
+func (c *Controller) ReceiveStatusEvent(ctx context.Context, userId string, status *integration.StatusEvent) error {
+	deviceInfo := status.GetDeviceInfo()
+	if deviceInfo == nil {
+		return errors.Join(model.ErrBadRequest, fmt.Errorf("statusInfo is nil"))
+	}
+	log.Logger.Debug("Status received", "dev_eui", deviceInfo.DevEui, "user", userId)
+	data := map[string]any{
+		"external_power_source":     status.ExternalPowerSource,
+		"battery_level_unavailable": status.BatteryLevelUnavailable,
+		"battery_level":             status.BatteryLevel,
+	}
+	return c.ReceiveEvent(ctx, userId, deviceInfo.DevEui, "status", data, status.Time.AsTime(), []*gw.UplinkRxInfo{}, deviceInfo.DeviceProfileId, status.DeduplicationId)
+}
+
+// setupRedisIngestion reads device events from the redis event stream of chirpstack instead of per-tenant http integrations.
+// Chirpstack only writes the stream, if monitoring.device_event_log_max_history is configured, and trims it to that length.
+// Entries left pending by crashed consumers are claimed periodically.
+func (c *Controller) setupRedisIngestion(ctx context.Context) error {
+	// "$" only applies to a new group, an existing group continues where it stopped
+	err := c.rdb.XGroupCreateMkStream(ctx, c.config.ChirpstackEventStream, chirpstackEventGroup, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	consumer, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	go func() {
+		// entries left pending by a previous run of this consumer are read first
+		lastId := "0"
+		lastClaim := time.Now()
+		for ctx.Err() == nil {
+			if time.Since(lastClaim) > chirpstackEventClaimInterval {
+				c.claimChirpstackStreamEvents(ctx, consumer)
+				lastClaim = time.Now()
+			}
+			streams, err := c.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+				Group:    chirpstackEventGroup,
+				Consumer: consumer,
+				Streams:  []string{c.config.ChirpstackEventStream, lastId},
+				Count:    10,
+				Block:    5 * time.Second,
+			}).Result()
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Logger.Error("unable to read chirpstack event stream", attributes.ErrorKey, err)
+					time.Sleep(5 * time.Second)
+				}
+				continue
+			}
+			messages := 0
+			for _, stream := range streams {
+				for _, message := range stream.Messages {
+					messages++
+					c.handleChirpstackStreamEvent(ctx, message)
+					err = c.rdb.XAck(ctx, c.config.ChirpstackEventStream, chirpstackEventGroup, message.ID).Err()
+					if err != nil {
+						log.Logger.Error("unable to acknowledge chirpstack event", attributes.ErrorKey, err, "id", message.ID)
+					}
+				}
+			}
+			if messages == 0 && lastId == "0" {
+				lastId = ">"
+			}
+		}
+	}()
+	return nil
+}
+
+// claimChirpstackStreamEvents handles entries of the chirpstack event stream, which were left pending by other consumers.
+func (c *Controller) claimChirpstackStreamEvents(ctx context.Context, consumer string) {
+	start := "0-0"
+	for ctx.Err() == nil {
+		messages, next, err := c.rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   c.config.ChirpstackEventStream,
+			Group:    chirpstackEventGroup,
+			Consumer: consumer,
+			MinIdle:  chirpstackEventClaimIdle,
+			Start:    start,
+			Count:    10,
+		}).Result()
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Logger.Error("unable to claim chirpstack events", attributes.ErrorKey, err)
+			}
+			return
+		}
+		for _, message := range messages {
+			log.Logger.Info("claimed pending chirpstack event", "id", message.ID)
+			c.handleChirpstackStreamEvent(ctx, message)
+			err = c.rdb.XAck(ctx, c.config.ChirpstackEventStream, chirpstackEventGroup, message.ID).Err()
+			if err != nil {
+				log.Logger.Error("unable to acknowledge chirpstack event", attributes.ErrorKey, err, "id", message.ID)
+			}
+		}
+		if next == "0-0" || next == "" {
+			return
+		}
+		start = next
+	}
+}
+
+// handleChirpstackStreamEvent handles an entry of the chirpstack event stream. Entries map the event type to the protobuf encoded event.
+// Errors are logged, like failed http integration calls the event is not retried.
+func (c *Controller) handleChirpstackStreamEvent(ctx context.Context, message redis.XMessage) {
+	for eventType, value := range message.Values {
+		b, ok := value.(string)
+		if !ok {
+			continue
+		}
+		err := c.handleChirpstackEvent(ctx, eventType, model.EventEncodingProtobuf, []byte(b))
+		if err != nil {
+			log.Logger.Error("unable to handle chirpstack event", attributes.ErrorKey, err, "event", eventType, "id", message.ID)
+		}
+	}
+}
+
+// UnmarshalEvent decodes an integration event of chirpstack.
+func UnmarshalEvent(encoding string, body []byte, v proto.Message) error {
+	switch encoding {
+	case model.EventEncodingProtobuf:
+		return proto.Unmarshal(body, v)
+	case model.EventEncodingJson:
+		return protojson.Unmarshal(body, v)
+	default:
+		return fmt.Errorf("unsupported encoding %s", encoding)
+	}
+}
+
+type chirpstackEvent interface {
+	proto.Message
+	GetDeviceInfo() *integration.DeviceInfo
+}
+
+// unmarshalChirpstackEvent decodes an integration event of the event type. Returns nil for unsupported event types.
+func unmarshalChirpstackEvent(eventType string, encoding string, body []byte) (chirpstackEvent, error) {
+	var event chirpstackEvent
+	switch eventType {
+	case "up":
+		event = &integration.UplinkEvent{}
+	case "join":
+		event = &integration.JoinEvent{}
+	case "status":
+		event = &integration.StatusEvent{}
+	default:
+		return nil, nil
+	}
+	err := UnmarshalEvent(encoding, body, event)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("unable to unmarshal chirpstack event"), err)
+	}
+	return event, nil
+}
+
+// handleChirpstackEvent dispatches an integration event, which was not received by the http integration of the tenant.
+// Unsupported event types are ignored.
+func (c *Controller) handleChirpstackEvent(ctx context.Context, eventType string, encoding string, body []byte) error {
+	event, err := unmarshalChirpstackEvent(eventType, encoding, body)
+	if err != nil || event == nil {
+		return err
+	}
+	return c.dispatchChirpstackEvent(ctx, event)
+}
+
+// dispatchChirpstackEvent passes the event to the platform user, who is the owner of the tenant of the device.
+// Events of unmanaged tenants are ignored.
+func (c *Controller) dispatchChirpstackEvent(ctx context.Context, event chirpstackEvent) error {
+	deviceInfo := event.GetDeviceInfo()
+	if deviceInfo == nil {
+		return fmt.Errorf("deviceInfo is nil")
+	}
+	userId, err := cache.Use(c.cache, "lpc_tenant_owner_"+deviceInfo.TenantId, func() (string, error) {
+		return c.getChirpstackTenantOwnerId(ctx, deviceInfo.TenantId)
+	}, nil, time.Minute)
+	if err != nil {
+		return errors.Join(fmt.Errorf("unable to get owner of chirpstack tenant %s", deviceInfo.TenantId), err)
+	}
+	if userId == "" {
+		return nil
+	}
+	switch e := event.(type) {
+	case *integration.UplinkEvent:
+		return c.ReceiveUplinkEvent(ctx, userId, e)
+	case *integration.JoinEvent:
+		return c.ReceiveJoinEvent(ctx, userId, e)
+	case *integration.StatusEvent:
+		return c.ReceiveStatusEvent(ctx, userId, e)
+	}
+	return nil
+}
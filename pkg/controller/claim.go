@@ -0,0 +1,287 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	device_repo "github.com/SENERGY-Platform/device-repository/lib/client"
+	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/chirpstack/chirpstack/api/go/v4/api"
+	"github.com/go-redis/redis/v8"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const claimCodeBytes = 10
+
+var errInvalidClaim = errors.Join(model.ErrForbidden, fmt.Errorf("invalid dev eui or claim code"))
+
+// CreateDeviceClaimCode attaches a new one-time claim code to an inventory device, replacing any previous code.
+// The code is only returned once, the connector keeps its hash. Requires an admin token.
+func (c *Controller) CreateDeviceClaimCode(ctx context.Context, token jwt.Token, deviceId string) (*model.DeviceClaimCode, error) {
+	if !token.IsAdmin() {
+		return nil, errors.Join(model.ErrForbidden, fmt.Errorf("only admins may create claim codes"))
+	}
+	device, err, code := c.deviceRepo.ReadExtendedDevice(deviceId, token.Token, models.Read, true)
+	if err != nil {
+		return nil, deviceRepoHandleErr(err, code)
+	}
+	if device.DeviceType == nil || !deviceTypeManagedByLorawanPlatformConnector(*device.DeviceType) {
+		return nil, errors.Join(model.ErrBadRequest, fmt.Errorf("device is not managed by %s", model.DeviceTypeAttributeManagedByValue))
+	}
+	b := make([]byte, claimCodeBytes)
+	_, err = rand.Read(b)
+	if err != nil {
+		return nil, err
+	}
+	claimCode := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)
+	claim := model.DeviceClaim{
+		DeviceId:  device.Id,
+		DevEui:    strings.ToLower(device.LocalId),
+		CodeHash:  hashClaimCode(claimCode),
+		CreatedAt: time.Now(),
+	}
+	claimJson, err := json.Marshal(claim)
+	if err != nil {
+		return nil, err
+	}
+	err = c.rdb.Set(ctx, fmt.Sprintf(model.RedisKeyFmtDeviceClaim, claim.DevEui), claimJson, 0).Err()
+	if err != nil {
+		return nil, err
+	}
+	return &model.DeviceClaimCode{
+		DeviceId:  claim.DeviceId,
+		DevEui:    claim.DevEui,
+		ClaimCode: claimCode,
+		CreatedAt: claim.CreatedAt,
+	}, nil
+}
+
+// DeleteDeviceClaimCode revokes the claim code of an inventory device. Requires an admin token.
+func (c *Controller) DeleteDeviceClaimCode(ctx context.Context, token jwt.Token, deviceId string) error {
+	if !token.IsAdmin() {
+		return errors.Join(model.ErrForbidden, fmt.Errorf("only admins may delete claim codes"))
+	}
+	device, err, code := c.deviceRepo.ReadDevice(deviceId, token.Token, models.Read)
+	if err != nil {
+		return deviceRepoHandleErr(err, code)
+	}
+	n, err := c.rdb.Del(ctx, fmt.Sprintf(model.RedisKeyFmtDeviceClaim, strings.ToLower(device.LocalId))).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.Join(model.ErrNotFound, fmt.Errorf("device has no claim code"))
+	}
+	return nil
+}
+
+// ClaimDevice reassigns an inventory device to the user of the token, if the claim code matches. The chirpstack device is moved
+// into the application of the user, keeping keys and activation. Key attributes are removed from the platform device,
+// so keys never become visible to the user.
+func (c *Controller) ClaimDevice(ctx context.Context, token jwt.Token, request model.DeviceClaimRequest) (result *model.DeviceClaimResult, err error) {
+	userId := token.GetUserId()
+	devEui := strings.ToLower(strings.TrimSpace(request.DevEui))
+	if !isHexOfLength(devEui, euiLength) {
+		return nil, errors.Join(model.ErrBadRequest, fmt.Errorf("dev eui must be %d hex encoded bytes", euiLength))
+	}
+	key := fmt.Sprintf(model.RedisKeyFmtDeviceClaim, devEui)
+	claimJson, err := c.rdb.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, errInvalidClaim
+	}
+	if err != nil {
+		return nil, err
+	}
+	var claim model.DeviceClaim
+	err = json.Unmarshal(claimJson, &claim)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashClaimCode(request.ClaimCode)), []byte(claim.CodeHash)) != 1 {
+		return nil, errInvalidClaim
+	}
+
+	// consume the code before claiming, so only one request may succeed
+	n, err := c.rdb.Del(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, errInvalidClaim
+	}
+	claimed := false
+	defer func() {
+		if err != nil && !claimed {
+			restoreErr := c.rdb.SetNX(context.Background(), key, claimJson, 0).Err()
+			if restoreErr != nil {
+				log.Logger.Error("unable to restore claim code", attributes.ErrorKey, restoreErr, "dev_eui", devEui)
+			}
+		}
+	}()
+
+	c.jwtMux.RLock()
+	adminToken := "Bearer " + c.jwt.AccessToken
+	c.jwtMux.RUnlock()
+	device, err, code := c.deviceRepo.ReadExtendedDevice(claim.DeviceId, adminToken, models.Read, true)
+	if err != nil {
+		return nil, deviceRepoHandleErr(err, code)
+	}
+	if strings.ToLower(device.LocalId) != devEui {
+		return nil, errors.Join(model.ErrBadRequest, fmt.Errorf("dev eui of device has changed since the claim code was created"))
+	}
+	if device.OwnerId == userId {
+		return nil, errors.Join(model.ErrBadRequest, fmt.Errorf("device is already owned by user"))
+	}
+
+	previousAppId, err := c.moveChirpDeviceToOwner(ctx, &device, userId)
+	if err != nil {
+		return nil, err
+	}
+	for _, attributeKey := range deviceKeyAttributes {
+		model.RemoveDeviceAttribute(attributeKey, &device.Device)
+	}
+	model.RemoveDeviceAttribute(model.DeviceAttributeReleasedKey, &device.Device)
+	err = c.setDeviceOwner(adminToken, device.Device, userId)
+	if err != nil {
+		undoErr := c.undoChirpDeviceMove(devEui, previousAppId)
+		if undoErr != nil {
+			log.Logger.Error("unable to move claimed device back to previous owner", attributes.ErrorKey, undoErr, "dev_eui", devEui, "app_id", previousAppId)
+		}
+		return nil, err
+	}
+	claimed = true
+	log.Logger.Info("device claimed", "device_id", device.Id, "dev_eui", devEui, "from_user_id", device.OwnerId, "to_user_id", userId)
+	return &model.DeviceClaimResult{
+		DeviceId: device.Id,
+		DevEui:   devEui,
+	}, nil
+}
+
+// moveChirpDeviceToOwner moves the chirpstack device of the platform device into the application of the new owner and returns the previous application.
+// If the chirpstack device does not exist yet, it is created in the application of the current owner first.
+func (c *Controller) moveChirpDeviceToOwner(ctx context.Context, device *models.ExtendedDevice, ownerId string) (previousAppId string, err error) {
+	devEui := strings.ToLower(device.LocalId)
+	appId, err := c.getChirpstackAppIdOfOwner(ctx, ownerId)
+	if err != nil {
+		return "", err
+	}
+	chirpDevice, err := c.chirpDevice.Get(ctx, &api.GetDeviceRequest{DevEui: devEui})
+	if status.Code(err) == codes.NotFound {
+		err = c.SyncDevice(ctx, device)
+		if err != nil {
+			return "", err
+		}
+		chirpDevice, err = c.chirpDevice.Get(ctx, &api.GetDeviceRequest{DevEui: devEui})
+	}
+	if err != nil {
+		return "", err
+	}
+	previousAppId = chirpDevice.Device.ApplicationId
+	if previousAppId == appId {
+		return previousAppId, nil
+	}
+	fromTenantId, err := c.getApplicationTenantId(ctx, previousAppId)
+	if err != nil {
+		return "", err
+	}
+	target := proto.CloneOf(chirpDevice.Device)
+	target.ApplicationId = appId
+	err = c.moveChirpDevice(ctx, chirpDevice.Device, target)
+	if err != nil {
+		return "", err
+	}
+	toTenantId, err := c.getApplicationTenantId(ctx, appId)
+	if err != nil {
+		log.Logger.Error("unable to resolve tenant of claimed device", attributes.ErrorKey, err, "dev_eui", devEui)
+	}
+	c.recordTransfer(ctx, model.Transfer{
+		Type:         model.TransferTypeDevice,
+		Eui:          devEui,
+		PlatformId:   device.Id,
+		FromUserId:   device.OwnerId,
+		ToUserId:     ownerId,
+		FromTenantId: fromTenantId,
+		ToTenantId:   toTenantId,
+		Reason:       model.TransferReasonClaimed,
+		Time:         time.Now(),
+	})
+	return previousAppId, nil
+}
+
+// undoChirpDeviceMove moves the chirpstack device back into its previous application after a failed claim.
+func (c *Controller) undoChirpDeviceMove(devEui string, previousAppId string) error {
+	ctx, cf := context.WithTimeout(context.Background(), time.Minute)
+	defer cf()
+	chirpDevice, err := c.chirpDevice.Get(ctx, &api.GetDeviceRequest{DevEui: devEui})
+	if err != nil {
+		return err
+	}
+	if chirpDevice.Device.ApplicationId == previousAppId {
+		return nil
+	}
+	target := proto.CloneOf(chirpDevice.Device)
+	target.ApplicationId = previousAppId
+	return c.moveChirpDevice(ctx, chirpDevice.Device, target)
+}
+
+// setDeviceOwner replaces all user permissions of the device with admin rights for the new owner and updates the owner of the device.
+func (c *Controller) setDeviceOwner(adminToken string, device models.Device, ownerId string) error {
+	resource, err, code := c.perm.GetResource(adminToken, model.PermissionsTopicDevices, device.Id)
+	if err != nil {
+		return deviceRepoHandleErr(err, code)
+	}
+	permissions := resource.ResourcePermissions
+	permissions.UserPermissions = map[string]permv2.PermissionsMap{
+		ownerId: {Read: true, Write: true, Execute: true, Administrate: true},
+	}
+	_, err, _ = c.perm.SetPermission(adminToken, model.PermissionsTopicDevices, device.Id, permissions)
+	if err != nil {
+		return err
+	}
+	device.OwnerId = ownerId
+	_, err, _ = c.deviceRepo.SetDevice(adminToken, device, device_repo.DeviceUpdateOptions{})
+	if err != nil {
+		_, restoreErr, _ := c.perm.SetPermission(adminToken, model.PermissionsTopicDevices, device.Id, resource.ResourcePermissions)
+		return errors.Join(err, restoreErr)
+	}
+	return nil
+}
+
+// hashClaimCode ignores case, whitespace and dashes, so users may enter codes in groups.
+func hashClaimCode(claimCode string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(claimCode)))
+	hash := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(hash[:])
+}
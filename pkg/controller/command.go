@@ -21,16 +21,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
 	platform_connector_lib "github.com/SENERGY-Platform/platform-connector-lib"
+	"github.com/SENERGY-Platform/service-commons/pkg/cache"
 	"github.com/chirpstack/chirpstack/api/go/v4/api"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
 func (c *Controller) HandleCommand(deviceId string, deviceLocalId string, serviceId string, serviceLocalId string, requestMsg platform_connector_lib.CommandRequestMsg) (responseMsg platform_connector_lib.CommandResponseMsg, qos platform_connector_lib.Qos, err error) {
+	if name, ok := strings.CutPrefix(serviceLocalId, model.CommandServiceLocalIdPrefix); ok {
+		return c.handleCatalogCommand(deviceLocalId, name, requestMsg)
+	}
 	serviceLocalIdUint, err := strconv.ParseUint(serviceLocalId, 10, 32)
 	if err != nil {
 		return nil, platform_connector_lib.SyncIdempotent, errors.Join(fmt.Errorf("unable to parse service local id into uint: %s", serviceId), err)
@@ -55,18 +61,60 @@ func (c *Controller) HandleCommand(deviceId string, deviceLocalId string, servic
 
 	ctx, cf := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cf()
-	resp, err := c.chirpDevice.Enqueue(ctx, &api.EnqueueDeviceQueueItemRequest{
-		QueueItem: &api.DeviceQueueItem{
-			DevEui: deviceLocalId,
-			FPort:  uint32(serviceLocalIdUint),
-			Object: object,
-		},
+	id, err := c.enqueueDownlink(ctx, &api.DeviceQueueItem{
+		DevEui: deviceLocalId,
+		FPort:  uint32(serviceLocalIdUint),
+		Object: object,
 	})
 	if err != nil {
 		return nil, platform_connector_lib.SyncIdempotent, errors.Join(fmt.Errorf("unable to enque request"), err)
 	}
 	responseMsg = platform_connector_lib.CommandResponseMsg{
-		model.ProtocolSegmentData: resp.Id,
+		model.ProtocolSegmentData: id,
+	}
+	return responseMsg, platform_connector_lib.SyncIdempotent, nil
+}
+
+// handleCatalogCommand enqueues a downlink command defined in the command catalog or the device profile tags.
+func (c *Controller) handleCatalogCommand(deviceLocalId string, name string, requestMsg platform_connector_lib.CommandRequestMsg) (responseMsg platform_connector_lib.CommandResponseMsg, qos platform_connector_lib.Qos, err error) {
+	ctx, cf := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cf()
+	device, err := c.chirpDevice.Get(ctx, &api.GetDeviceRequest{DevEui: deviceLocalId})
+	if err != nil {
+		return nil, platform_connector_lib.SyncIdempotent, errors.Join(fmt.Errorf("unable to read chirpstack device"), err)
+	}
+	commands, err := cache.Use(c.cache, "lpc_commands_"+device.Device.DeviceProfileId, func() ([]model.DownlinkCommand, error) {
+		profile, err := c.chirpDeviceProfile.Get(ctx, &api.GetDeviceProfileRequest{Id: device.Device.DeviceProfileId})
+		if err != nil {
+			return nil, err
+		}
+		return c.getDownlinkCommands(profile.DeviceProfile)
+	}, nil, time.Minute)
+	if err != nil {
+		return nil, platform_connector_lib.SyncIdempotent, errors.Join(fmt.Errorf("unable to get downlink commands"), err)
+	}
+	idx := slices.IndexFunc(commands, func(command model.DownlinkCommand) bool { return command.Name == name })
+	if idx == -1 {
+		return nil, platform_connector_lib.SyncIdempotent, fmt.Errorf("unknown command %s", name)
+	}
+
+	var data any
+	if raw := requestMsg[model.ProtocolSegmentData]; raw != "" {
+		err = json.Unmarshal([]byte(raw), &data)
+		if err != nil {
+			return nil, platform_connector_lib.SyncIdempotent, errors.Join(fmt.Errorf("unable to parse request: %s", raw), err)
+		}
+	}
+	item, err := prepareCommandQueueItem(commands[idx], deviceLocalId, data)
+	if err != nil {
+		return nil, platform_connector_lib.SyncIdempotent, err
+	}
+	id, err := c.enqueueDownlink(ctx, item)
+	if err != nil {
+		return nil, platform_connector_lib.SyncIdempotent, errors.Join(fmt.Errorf("unable to enque request"), err)
+	}
+	responseMsg = platform_connector_lib.CommandResponseMsg{
+		model.ProtocolSegmentData: id,
 	}
 	return responseMsg, platform_connector_lib.SyncIdempotent, nil
 }
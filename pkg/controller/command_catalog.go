@@ -0,0 +1,232 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/chirpstack/chirpstack/api/go/v4/api"
+	"google.golang.org/protobuf/types/known/structpb"
+	"sigs.k8s.io/yaml"
+)
+
+func loadCommandCatalog(path string) (catalog model.CommandCatalog, err error) {
+	if path == "" {
+		return catalog, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return catalog, err
+	}
+	b, err = yaml.YAMLToJSON(b) // json is valid yaml
+	if err != nil {
+		return catalog, err
+	}
+	err = json.Unmarshal(b, &catalog)
+	if err != nil {
+		return catalog, err
+	}
+	for profile, commands := range catalog.DeviceProfiles {
+		err = validateDownlinkCommands(commands)
+		if err != nil {
+			return catalog, errors.Join(fmt.Errorf("invalid commands of device profile %s", profile), err)
+		}
+	}
+	return catalog, nil
+}
+
+func validateDownlinkCommands(commands []model.DownlinkCommand) error {
+	names := map[string]bool{}
+	for _, command := range commands {
+		if command.Name == "" {
+			return fmt.Errorf("command without name")
+		}
+		if names[command.Name] {
+			return fmt.Errorf("duplicate command %s", command.Name)
+		}
+		names[command.Name] = true
+		inputNames := map[string]bool{}
+		for _, input := range command.Inputs {
+			if input.Name == "" {
+				return fmt.Errorf("command %s: input without name", command.Name)
+			}
+			if inputNames[input.Name] {
+				return fmt.Errorf("command %s: duplicate input %s", command.Name, input.Name)
+			}
+			inputNames[input.Name] = true
+			switch models.Type(input.Type) {
+			case models.String, models.Integer, models.Float, models.Boolean:
+			default:
+				return fmt.Errorf("command %s: input %s has unsupported type %s", command.Name, input.Name, input.Type)
+			}
+		}
+		if command.FPort == 0 || command.FPort > 223 {
+			return fmt.Errorf("command %s: f_port must be between 1 and 223", command.Name)
+		}
+		switch command.Encoder {
+		case "", model.DownlinkEncoderCodec:
+		case model.DownlinkEncoderHex, model.DownlinkEncoderBase64:
+			if len(command.Inputs) != 1 || command.Inputs[0].Name != "payload" || models.Type(command.Inputs[0].Type) != models.String {
+				return fmt.Errorf("command %s: encoder %s requires the single string input payload", command.Name, command.Encoder)
+			}
+		case model.DownlinkEncoderStatic:
+			if _, err := hex.DecodeString(command.Payload); err != nil || command.Payload == "" {
+				return fmt.Errorf("command %s: encoder static requires a hex encoded payload", command.Name)
+			}
+		default:
+			return fmt.Errorf("command %s: unknown encoder %s", command.Name, command.Encoder)
+		}
+	}
+	return nil
+}
+
+// getDownlinkCommands returns the commands of the device profile. The device profile tag overrides the catalog file.
+func (c *Controller) getDownlinkCommands(profile *api.DeviceProfile) ([]model.DownlinkCommand, error) {
+	if tag, ok := profile.Tags[model.DeviceProfileTagCommands]; ok {
+		commands := []model.DownlinkCommand{}
+		err := json.Unmarshal([]byte(tag), &commands)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("unable to parse device profile tag %s", model.DeviceProfileTagCommands), err)
+		}
+		return commands, validateDownlinkCommands(commands)
+	}
+	if commands, ok := c.commands.DeviceProfiles[profile.Id]; ok {
+		return commands, nil
+	}
+	return c.commands.DeviceProfiles[profile.Name], nil
+}
+
+// prepareCommandServices creates REQUEST services for the commands. Ids are kept from the services of base.
+func (c *Controller) prepareCommandServices(commands []model.DownlinkCommand, base *models.DeviceType) []models.Service {
+	services := []models.Service{}
+	for _, command := range commands {
+		service := models.Service{
+			LocalId:     model.CommandServiceLocalIdPrefix + command.Name,
+			Name:        command.Name,
+			Description: command.Description,
+			Interaction: models.REQUEST,
+			ProtocolId:  c.config.ProtocolId,
+			Inputs:      []models.Content{},
+			Outputs:     []models.Content{},
+		}
+		var baseService *models.Service
+		if base != nil {
+			for i := range base.Services {
+				if base.Services[i].LocalId == service.LocalId {
+					baseService = &base.Services[i]
+					service.Id = baseService.Id
+					service.ServiceGroupKey = baseService.ServiceGroupKey
+					service.Attributes = baseService.Attributes
+					break
+				}
+			}
+		}
+		if len(command.Inputs) > 0 {
+			content := models.Content{
+				Serialization:     models.JSON,
+				ProtocolSegmentId: c.config.ProtocolDataSegmentId,
+				ContentVariable: models.ContentVariable{
+					Name: "root",
+					Type: models.Structure,
+				},
+			}
+			var baseContent *models.Content
+			if baseService != nil && len(baseService.Inputs) > 0 {
+				baseContent = &baseService.Inputs[0]
+				content.Id = baseContent.Id
+				content.ContentVariable.Id = baseContent.ContentVariable.Id
+			}
+			for _, input := range command.Inputs {
+				cv := models.ContentVariable{
+					Name:             input.Name,
+					Type:             models.Type(input.Type),
+					CharacteristicId: input.CharacteristicId,
+					FunctionId:       input.FunctionId,
+					AspectId:         input.AspectId,
+				}
+				if baseContent != nil {
+					for _, sub := range baseContent.ContentVariable.SubContentVariables {
+						if sub.Name == cv.Name {
+							cv.Id = sub.Id
+							break
+						}
+					}
+				}
+				content.ContentVariable.SubContentVariables = append(content.ContentVariable.SubContentVariables, cv)
+			}
+			service.Inputs = append(service.Inputs, content)
+		}
+		services = append(services, service)
+	}
+	return services
+}
+
+// prepareCommandQueueItem encodes the request data according to the encoder of the command.
+func prepareCommandQueueItem(command model.DownlinkCommand, devEui string, data any) (*api.DeviceQueueItem, error) {
+	item := &api.DeviceQueueItem{
+		DevEui:    devEui,
+		FPort:     command.FPort,
+		Confirmed: command.Confirmed,
+	}
+	inputs, _ := data.(map[string]any)
+	switch command.Encoder {
+	case model.DownlinkEncoderStatic:
+		b, err := hex.DecodeString(command.Payload)
+		if err != nil {
+			return nil, err
+		}
+		item.Data = b
+	case model.DownlinkEncoderHex, model.DownlinkEncoderBase64:
+		payload, ok := inputs["payload"].(string)
+		if !ok {
+			return nil, fmt.Errorf("command %s: missing string input payload", command.Name)
+		}
+		var b []byte
+		var err error
+		if command.Encoder == model.DownlinkEncoderHex {
+			b, err = hex.DecodeString(strings.TrimPrefix(payload, "0x"))
+		} else {
+			b, err = base64.StdEncoding.DecodeString(payload)
+		}
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("command %s: unable to decode payload", command.Name), err)
+		}
+		item.Data = b
+	default:
+		object := map[string]any{}
+		for key, value := range inputs {
+			object[key] = value
+		}
+		object["command"] = command.Name
+		s, err := structpb.NewStruct(object)
+		if err != nil {
+			return nil, err
+		}
+		item.Object = s
+	}
+	log.Logger.Debug("prepared downlink command", "dev_eui", devEui, "command", command.Name, "f_port", command.FPort, "encoder", command.Encoder)
+	return item, nil
+}
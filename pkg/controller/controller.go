@@ -19,6 +19,9 @@ package controller
 import (
 	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
@@ -27,8 +30,13 @@ import (
 	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
 	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/configuration"
 	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/secrets"
+	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/client"
 	platform_connector_lib "github.com/SENERGY-Platform/platform-connector-lib"
+	"github.com/SENERGY-Platform/service-commons/pkg/cache"
 	"github.com/chirpstack/chirpstack/api/go/v4/api"
+	paho "github.com/eclipse/paho.mqtt.golang"
 	"github.com/go-redis/redis/v8"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -47,7 +55,17 @@ type Controller struct {
 	jwtMux             sync.RWMutex
 	connector          *platform_connector_lib.Connector
 	deviceRepo         device_repo.Interface
+	perm               permv2.Client
 	rdb                *redis.Client
+	secrets            *secrets.Store // nil, if no secret store is configured
+	commands           model.CommandCatalog
+	annotationRules    []annotationRule
+	deviceTypeUpdates  deviceTypeUpdates
+	mqtt               paho.Client  // nil, if neither mqtt ingestion nor mqtt downlinks are enabled
+	cache              *cache.Cache // cache of the platform connector or a local cache without kafka
+	sink               EventSink
+	webhookQueue       chan webhookDelivery // nil, if webhooks are disabled
+	webhookClient      *http.Client
 }
 
 func New(config configuration.Config, ctx context.Context) (*Controller, error) {
@@ -76,7 +94,7 @@ func New(config configuration.Config, ctx context.Context) (*Controller, error)
 	gocloakClient := gocloak.NewClient(config.KeycloakUrl)
 	gocloakCtx, gocloakCf := context.WithTimeout(ctx, 10*time.Second)
 	defer gocloakCf()
-	jwt, err := gocloakClient.LoginClient(gocloakCtx, config.KeycloakClientId, config.KeycloakClientSecret, "master")
+	jwt, err := gocloakClient.LoginClient(gocloakCtx, config.KeycloakClientId, config.KeycloakClientSecret, config.KeycloakRealm)
 	if err != nil {
 		return nil, err
 	}
@@ -86,6 +104,21 @@ func New(config configuration.Config, ctx context.Context) (*Controller, error)
 		Addr: config.RedisUrl,
 	})
 
+	secretStore, err := secrets.New(config, rdb)
+	if err != nil {
+		return nil, err
+	}
+
+	commands, err := loadCommandCatalog(config.CommandCatalogFile)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("unable to load command catalog"), err)
+	}
+
+	annotationRules, err := loadAnnotationRules(config.AnnotationRulesFile)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("unable to load annotation rules"), err)
+	}
+
 	// create controller
 	controller := &Controller{
 		config:             config,
@@ -99,6 +132,11 @@ func New(config configuration.Config, ctx context.Context) (*Controller, error)
 		gocloakClient:      gocloakClient,
 		jwtMux:             sync.RWMutex{},
 		rdb:                rdb,
+		perm:               permv2.New(config.PermissionsV2Url),
+		secrets:            secretStore,
+		commands:           commands,
+		annotationRules:    annotationRules,
+		deviceTypeUpdates:  deviceTypeUpdates{pending: map[string]*pendingDeviceType{}},
 	}
 	controller.deviceRepo = device_repo.NewClient(config.DeviceRepoUrl, func() (token string, err error) {
 		controller.jwtMux.RLock()
@@ -115,7 +153,7 @@ func New(config configuration.Config, ctx context.Context) (*Controller, error)
 				return
 			case <-timer.C:
 				controller.jwtMux.Lock()
-				jwt, err := gocloakClient.LoginClient(ctx, config.KeycloakClientId, config.KeycloakClientSecret, "master")
+				jwt, err := gocloakClient.LoginClient(ctx, config.KeycloakClientId, config.KeycloakClientSecret, config.KeycloakRealm)
 				if err != nil {
 					log.Logger.Error("failed to refresh token", attributes.ErrorKey, err)
 					controller.jwtMux.Unlock()
@@ -135,6 +173,43 @@ func New(config configuration.Config, ctx context.Context) (*Controller, error)
 		if err != nil {
 			return nil, err
 		}
+		controller.cache = controller.connector.IotCache.GetCache()
+	} else {
+		log.Logger.Warn("kafka is not configured, running without platform connector")
+		controller.cache, err = cache.New(cache.Config{})
+		if err != nil {
+			return nil, err
+		}
+	}
+	controller.sink, err = newEventSink(ctx, config, controller.connector)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.WebhookWorkers > 0 {
+		controller.setupWebhooks(ctx)
+	}
+	if config.UplinkBuffer {
+		err = controller.setupUplinkBuffer(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if config.IngestionMode == model.IngestionModeMqtt || config.MqttDownlinks {
+		err = controller.setupMqtt(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch config.IngestionMode {
+	case model.IngestionModeHttp, model.IngestionModeMqtt:
+	case model.IngestionModeRedis:
+		err = controller.setupRedisIngestion(ctx)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown ingestion mode %s", config.IngestionMode)
 	}
 
 	if !config.DisableSync {
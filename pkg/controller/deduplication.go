@@ -0,0 +1,64 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// served by the metrics server of the platform-connector-lib
+var suppressedDuplicates = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "lorawan_platform_connector_suppressed_duplicate_events_total",
+	Help: "Events not forwarded to the platform, because their chirpstack deduplication id has already been processed",
+})
+
+// claimDeduplicationId returns true, if the event has already been processed.
+// Status events share the deduplication id of their uplink, so ids are scoped by the local service id.
+// Redis errors are logged and the event is treated as new, duplicates are preferred over data loss.
+func (c *Controller) claimDeduplicationId(ctx context.Context, localServiceId string, deduplicationId string) (duplicate bool) {
+	if deduplicationId == "" || c.config.DeduplicationTtl <= 0 {
+		return false
+	}
+	claimed, err := c.rdb.SetNX(ctx, fmt.Sprintf(model.RedisKeyFmtDeduplication, localServiceId, deduplicationId), 1, c.config.DeduplicationTtl).Result()
+	if err != nil {
+		log.Logger.Error("unable to check deduplication id", attributes.ErrorKey, err, "deduplication_id", deduplicationId)
+		return false
+	}
+	if !claimed {
+		suppressedDuplicates.Inc()
+		return true
+	}
+	return false
+}
+
+// releaseDeduplicationId allows a retry of an event, which could not be processed.
+func (c *Controller) releaseDeduplicationId(ctx context.Context, localServiceId string, deduplicationId string) {
+	if deduplicationId == "" || c.config.DeduplicationTtl <= 0 {
+		return
+	}
+	err := c.rdb.Del(ctx, fmt.Sprintf(model.RedisKeyFmtDeduplication, localServiceId, deduplicationId)).Err()
+	if err != nil {
+		log.Logger.Error("unable to release deduplication id", attributes.ErrorKey, err, "deduplication_id", deduplicationId)
+	}
+}
@@ -0,0 +1,344 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/chirpstack/chirpstack/api/go/v4/api"
+	"github.com/chirpstack/chirpstack/api/go/v4/common"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+const deviceImportMaxRows = 1000
+const deviceImportWorkers = 10
+const deviceImportJobExpiration = 24 * time.Hour
+
+type deviceImportEntry struct {
+	device     models.Device
+	deviceType *models.DeviceType
+}
+
+// ImportDevices validates all rows of a device manifest. If every row is valid, a job creating the platform and chirpstack
+// devices is started in the background. Otherwise, the returned job has the status model.DeviceImportJobStatusRejected and nothing is created.
+func (c *Controller) ImportDevices(ctx context.Context, token jwt.Token, rows []model.DeviceImportRow) (*model.DeviceImportJob, error) {
+	if len(rows) == 0 {
+		return nil, errors.Join(model.ErrBadRequest, fmt.Errorf("manifest contains no devices"))
+	}
+	if len(rows) > deviceImportMaxRows {
+		return nil, errors.Join(model.ErrBadRequest, fmt.Errorf("manifest contains more than %d devices", deviceImportMaxRows))
+	}
+	userId := token.GetUserId()
+	appId, err := c.getChirpstackAppIdOfOwner(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &model.DeviceImportJob{
+		Id:        uuid.NewString(),
+		UserId:    userId,
+		Status:    model.DeviceImportJobStatusRunning,
+		CreatedAt: time.Now(),
+		Results:   make([]model.DeviceImportResult, len(rows)),
+	}
+
+	// resolve device types of all referenced device profiles
+	deviceTypes := map[string]*models.DeviceType{}
+	for _, row := range rows {
+		if _, ok := deviceTypes[row.DeviceProfileId]; ok || row.DeviceProfileId == "" {
+			continue
+		}
+		deviceTypes[row.DeviceProfileId], err = c.getDeviceTypeOfDeviceProfile(row.DeviceProfileId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// validate every row before creating anything
+	entries := make([]deviceImportEntry, len(rows))
+	seen := map[string]int{}
+	for i, row := range rows {
+		devEui := strings.ToLower(row.DevEui)
+		job.Results[i] = model.DeviceImportResult{
+			Row:    i + 1,
+			DevEui: devEui,
+			Status: model.DeviceImportResultStatusPending,
+			Errors: []model.DeviceValidationError{},
+		}
+		if j, ok := seen[devEui]; ok {
+			job.Results[i].Errors = append(job.Results[i].Errors, model.DeviceValidationError{
+				Field:   model.DeviceValidationFieldLocalId,
+				Message: fmt.Sprintf("dev eui is duplicated in row %d", j+1),
+			})
+		} else {
+			seen[devEui] = i
+		}
+		if row.Name == "" {
+			job.Results[i].Errors = append(job.Results[i].Errors, model.DeviceValidationError{
+				Field:   model.DeviceValidationFieldName,
+				Message: "name must be set",
+			})
+		}
+		entries[i] = deviceImportEntry{
+			device:     deviceImportRowToDevice(row, userId),
+			deviceType: deviceTypes[row.DeviceProfileId],
+		}
+		if entries[i].deviceType != nil {
+			entries[i].device.DeviceTypeId = entries[i].deviceType.Id
+		} else {
+			job.Results[i].Errors = append(job.Results[i].Errors, model.DeviceValidationError{
+				Field:   model.DeviceValidationFieldDeviceProfileId,
+				Message: fmt.Sprintf("device profile %s is unknown or not synced", row.DeviceProfileId),
+			})
+		}
+	}
+
+	wg := sync.WaitGroup{}
+	mux := sync.Mutex{}
+	sem := make(chan struct{}, deviceImportWorkers)
+	for i := range entries {
+		if entries[i].deviceType == nil {
+			continue
+		}
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			validationErrors, err2 := c.validateDevice(ctx, &entries[i].device, entries[i].deviceType, appId)
+			if err2 == nil {
+				err2 = c.fillDeviceImportSessionKeys(ctx, &entries[i])
+			}
+			if err2 == nil {
+				_, _, code := c.deviceRepo.ReadDeviceByLocalId(userId, entries[i].device.LocalId, token.Token, models.Read)
+				if code == http.StatusOK {
+					validationErrors = append(validationErrors, model.DeviceValidationError{
+						Field:   model.DeviceValidationFieldLocalId,
+						Message: "device already exists in the platform",
+					})
+				}
+			}
+			mux.Lock()
+			defer mux.Unlock()
+			if err2 != nil {
+				err = errors.Join(err, err2)
+				return
+			}
+			job.Results[i].Errors = append(job.Results[i].Errors, validationErrors...)
+		})
+	}
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	rejected := false
+	for i := range job.Results {
+		if len(job.Results[i].Errors) > 0 {
+			job.Results[i].Status = model.DeviceImportResultStatusInvalid
+			rejected = true
+		}
+	}
+	if rejected {
+		job.Status = model.DeviceImportJobStatusRejected
+		now := time.Now()
+		job.FinishedAt = &now
+		return job, nil
+	}
+
+	err = c.storeDeviceImportJob(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	// the job is modified by the import workers, the caller gets a snapshot
+	result := copyDeviceImportJob(job)
+	go c.runDeviceImportJob(job, entries)
+	return result, nil
+}
+
+func copyDeviceImportJob(job *model.DeviceImportJob) *model.DeviceImportJob {
+	result := *job
+	if job.FinishedAt != nil {
+		finishedAt := *job.FinishedAt
+		result.FinishedAt = &finishedAt
+	}
+	result.Results = make([]model.DeviceImportResult, len(job.Results))
+	for i, r := range job.Results {
+		r.Errors = slices.Clone(r.Errors)
+		result.Results[i] = r
+	}
+	return &result
+}
+
+// GetDeviceImportJob returns the import job with the given id, if it was started by the user of the token.
+func (c *Controller) GetDeviceImportJob(ctx context.Context, token jwt.Token, jobId string) (*model.DeviceImportJob, error) {
+	b, err := c.rdb.Get(ctx, fmt.Sprintf(model.RedisKeyFmtDeviceImportJob, jobId)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errors.Join(model.ErrNotFound, fmt.Errorf("import job %s not found", jobId))
+		}
+		return nil, err
+	}
+	var job model.DeviceImportJob
+	err = json.Unmarshal(b, &job)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserId != token.GetUserId() && !token.IsAdmin() {
+		return nil, errors.Join(model.ErrNotFound, fmt.Errorf("import job %s not found", jobId))
+	}
+	return &job, nil
+}
+
+func (c *Controller) runDeviceImportJob(job *model.DeviceImportJob, entries []deviceImportEntry) {
+	mux := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	sem := make(chan struct{}, deviceImportWorkers)
+	for i := range entries {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			deviceId, err := c.importDevice(&entries[i])
+			mux.Lock()
+			defer mux.Unlock()
+			job.Results[i].DeviceId = deviceId
+			if err != nil {
+				log.Logger.Error("unable to import device", attributes.ErrorKey, err, "job_id", job.Id, "dev_eui", entries[i].device.LocalId)
+				job.Results[i].Status = model.DeviceImportResultStatusFailed
+				job.Results[i].Error = err.Error()
+			} else {
+				job.Results[i].Status = model.DeviceImportResultStatusCreated
+			}
+			ctx, cf := context.WithTimeout(context.Background(), 10*time.Second)
+			err = c.storeDeviceImportJob(ctx, job)
+			cf()
+			if err != nil {
+				log.Logger.Error("unable to store import job", attributes.ErrorKey, err, "job_id", job.Id)
+			}
+		})
+	}
+	wg.Wait()
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = model.DeviceImportJobStatusDone
+	ctx, cf := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cf()
+	err := c.storeDeviceImportJob(ctx, job)
+	if err != nil {
+		log.Logger.Error("unable to store import job", attributes.ErrorKey, err, "job_id", job.Id)
+	}
+}
+
+// importDevice creates the platform device and pushes it to chirpstack. Returns the id of the platform device, if it was created.
+func (c *Controller) importDevice(entry *deviceImportEntry) (deviceId string, err error) {
+	device, err := c.createPlatformDevice(entry.device.OwnerId, entry.device)
+	if err != nil {
+		return "", errors.Join(fmt.Errorf("unable to create platform device"), err)
+	}
+	ctx, cf := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cf()
+	err = c.SyncDevice(ctx, &models.ExtendedDevice{
+		Device:     device,
+		DeviceType: entry.deviceType,
+	})
+	if err != nil {
+		return device.Id, errors.Join(fmt.Errorf("unable to create chirpstack device"), err)
+	}
+	return device.Id, nil
+}
+
+// fillDeviceImportSessionKeys fills the session keys of LoRaWAN 1.0.x ABP devices, which only know a single network session key.
+func (c *Controller) fillDeviceImportSessionKeys(ctx context.Context, entry *deviceImportEntry) error {
+	var nwkSEncKey string
+	hasSNwkSIntKey, hasFNwkSIntKey := false, false
+	for _, a := range entry.device.Attributes {
+		switch a.Key {
+		case model.DeviceAttributeNwkSEncKey:
+			nwkSEncKey = a.Value
+		case model.DeviceAttributeSNwkSIntKey:
+			hasSNwkSIntKey = true
+		case model.DeviceAttributeFNwkSIntKey:
+			hasFNwkSIntKey = true
+		}
+	}
+	if nwkSEncKey == "" || (hasSNwkSIntKey && hasFNwkSIntKey) {
+		return nil
+	}
+	profile, err := c.chirpDeviceProfile.Get(ctx, &api.GetDeviceProfileRequest{Id: getDeviceTypeDeviceProfileId(entry.deviceType)})
+	if err != nil {
+		return err
+	}
+	if profile.DeviceProfile.SupportsOtaa || profile.DeviceProfile.MacVersion == common.MacVersion_LORAWAN_1_1_0 {
+		return nil
+	}
+	if !hasSNwkSIntKey {
+		entry.device.Attributes = append(entry.device.Attributes, models.Attribute{Key: model.DeviceAttributeSNwkSIntKey, Value: nwkSEncKey, Origin: model.AttributeOrigin})
+	}
+	if !hasFNwkSIntKey {
+		entry.device.Attributes = append(entry.device.Attributes, models.Attribute{Key: model.DeviceAttributeFNwkSIntKey, Value: nwkSEncKey, Origin: model.AttributeOrigin})
+	}
+	return nil
+}
+
+func (c *Controller) storeDeviceImportJob(ctx context.Context, job *model.DeviceImportJob) error {
+	b, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return c.rdb.Set(ctx, fmt.Sprintf(model.RedisKeyFmtDeviceImportJob, job.Id), b, deviceImportJobExpiration).Err()
+}
+
+func deviceImportRowToDevice(row model.DeviceImportRow, ownerId string) models.Device {
+	device := models.Device{
+		LocalId:    strings.ToLower(row.DevEui),
+		Name:       row.Name,
+		OwnerId:    ownerId,
+		Attributes: []models.Attribute{},
+	}
+	for _, kv := range [][2]string{
+		{model.DeviceAttributeJoinEuiKey, row.JoinEui},
+		{model.DeviceAttributeAppKey, row.AppKey},
+		{model.DeviceAttributeNwkKey, row.NwkKey},
+		{model.DeviceAttributeDevAddrKey, row.DevAddr},
+		{model.DeviceAttributeAppSKey, row.AppSKey},
+		{model.DeviceAttributeNwkSEncKey, row.NwkSEncKey},
+		{model.DeviceAttributeSNwkSIntKey, row.SNwkSIntKey},
+		{model.DeviceAttributeFNwkSIntKey, row.FNwkSIntKey},
+	} {
+		if kv[1] == "" {
+			continue
+		}
+		device.Attributes = append(device.Attributes, models.Attribute{
+			Key:    kv[0],
+			Value:  strings.ToLower(kv[1]),
+			Origin: model.AttributeOrigin,
+		})
+	}
+	return device
+}
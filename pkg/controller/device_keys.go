@@ -0,0 +1,199 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/chirpstack/chirpstack/api/go/v4/api"
+)
+
+const redactedKeyFingerprintLength = 8
+
+var errDuplicateDeviceKeys = errors.Join(model.ErrConflict, fmt.Errorf("the chirpstack device belongs to another tenant, keys were not set"))
+
+// SetDeviceKeys pushes new keys of a device to chirpstack. Empty keys remain unchanged.
+// The token has to grant write access to the device.
+func (c *Controller) SetDeviceKeys(ctx context.Context, token jwt.Token, deviceId string, keys model.DeviceKeys) error {
+	device, err, code := c.deviceRepo.ReadExtendedDevice(deviceId, token.Token, models.Write, true)
+	if err != nil {
+		return deviceRepoHandleErr(err, code)
+	}
+	if device.DeviceType == nil || !deviceTypeManagedByLorawanPlatformConnector(*device.DeviceType) {
+		return errors.Join(model.ErrBadRequest, fmt.Errorf("device is not managed by %s", model.DeviceTypeAttributeManagedByValue))
+	}
+	if getAttributeValue(device.Attributes, model.DeviceAttributeDuplicateKey) == "true" {
+		return errDuplicateDeviceKeys
+	}
+	for key, value := range map[string]string{
+		model.DeviceAttributeAppKey:      keys.AppKey,
+		model.DeviceAttributeGenAppKey:   keys.GenAppKey,
+		model.DeviceAttributeNwkKey:      keys.NwkKey,
+		model.DeviceAttributeAppSKey:     keys.AppSKey,
+		model.DeviceAttributeNwkSEncKey:  keys.NwkSEncKey,
+		model.DeviceAttributeSNwkSIntKey: keys.SNwkSIntKey,
+		model.DeviceAttributeFNwkSIntKey: keys.FNwkSIntKey,
+	} {
+		if value == "" {
+			continue
+		}
+		value = strings.ToLower(value)
+		if !isHexOfLength(value, keyLength) {
+			return errors.Join(model.ErrBadRequest, fmt.Errorf("%s must be %d hex encoded bytes", key, keyLength))
+		}
+		model.UpsertDeviceAttribute(models.Attribute{
+			Key:    key,
+			Value:  value,
+			Origin: model.AttributeOriginWebUI,
+		}, &device.Device)
+	}
+	err = c.SyncDevice(ctx, &device)
+	if err != nil {
+		return err
+	}
+	if getAttributeValue(device.Attributes, model.DeviceAttributeDuplicateKey) == "true" {
+		return errDuplicateDeviceKeys
+	}
+	if c.config.DeviceKeysWriteOnly {
+		return nil // with write-only keys, the sync has already updated the platform device with redacted keys
+	}
+	return c.updatePlatformDevice(device.OwnerId, device.Device)
+}
+
+// redactDeviceKeys replaces the key attributes of the platform device with fingerprints, if write-only keys are enabled.
+// Chirpstack remains the source of truth for the keys. With a secret store, the keys are kept there as well and the attributes reference them,
+// so keys lost in chirpstack can be restored. Must only be called after the keys have been pushed to chirpstack.
+func (c *Controller) redactDeviceKeys(ctx context.Context, platformDevice *models.ExtendedDevice) error {
+	updated, err := c.redactDeviceKeyAttributes(ctx, &platformDevice.Device)
+	if err != nil || !updated {
+		return err
+	}
+	return c.updatePlatformDevice(platformDevice.OwnerId, platformDevice.Device)
+}
+
+// redactDeviceKeyAttributes works like redactDeviceKeys, but does not update the platform device.
+func (c *Controller) redactDeviceKeyAttributes(ctx context.Context, device *models.Device) (updated bool, err error) {
+	if !c.config.DeviceKeysWriteOnly {
+		return false, nil
+	}
+	for i, a := range device.Attributes {
+		if a.Value == "" || isRedactedKey(a.Value) || !slices.Contains(deviceKeyAttributes, a.Key) {
+			continue
+		}
+		value := redactKey(a.Value)
+		if c.secrets != nil {
+			secretId := fmt.Sprintf(model.SecretIdFmtDeviceKey, strings.ToLower(device.LocalId), a.Key)
+			err = c.secrets.Put(ctx, secretId, []byte(strings.ToLower(a.Value)), model.AttributeOrigin)
+			if err != nil {
+				return updated, err
+			}
+			value = model.DeviceKeySecretPrefix + secretId
+		}
+		device.Attributes[i].Value = value
+		updated = true
+	}
+	return updated, nil
+}
+
+func (c *Controller) deleteDeviceKeySecrets(ctx context.Context, devEui string) {
+	if c.secrets == nil {
+		return
+	}
+	for _, key := range deviceKeyAttributes {
+		err := c.secrets.Delete(ctx, fmt.Sprintf(model.SecretIdFmtDeviceKey, strings.ToLower(devEui), key), model.AttributeOrigin)
+		if err != nil {
+			log.Logger.Error("unable to delete device key secret", attributes.ErrorKey, err, "dev_eui", devEui, "key", key)
+		}
+	}
+}
+
+// redactKey returns a placeholder containing a fingerprint of the key, so users may still check which key is in use.
+func redactKey(value string) string {
+	hash := sha256.Sum256([]byte(strings.ToLower(value)))
+	return model.DeviceKeyRedactedPrefix + hex.EncodeToString(hash[:])[:redactedKeyFingerprintLength]
+}
+
+func isRedactedKey(value string) bool {
+	return strings.HasPrefix(value, model.DeviceKeyRedactedPrefix) || strings.HasPrefix(value, model.DeviceKeySecretPrefix)
+}
+
+// mergeRedactedKeys replaces redacted values with the keys known to chirpstack. Returns nil, if no keys remain.
+func (c *Controller) mergeRedactedKeys(ctx context.Context, devEui string, keys *api.DeviceKeys, existing *api.DeviceKeys) *api.DeviceKeys {
+	if keys == nil {
+		return nil
+	}
+	if existing == nil {
+		existing = &api.DeviceKeys{}
+	}
+	keys.AppKey = c.mergeRedactedKey(ctx, devEui, model.DeviceAttributeAppKey, keys.AppKey, existing.AppKey, emptyKey)
+	keys.NwkKey = c.mergeRedactedKey(ctx, devEui, model.DeviceAttributeNwkKey, keys.NwkKey, existing.NwkKey, emptyKey)
+	keys.GenAppKey = c.mergeRedactedKey(ctx, devEui, model.DeviceAttributeGenAppKey, keys.GenAppKey, existing.GenAppKey, emptyKey)
+	if keys.AppKey == emptyKey && keys.GenAppKey == emptyKey && keys.NwkKey == emptyKey {
+		return nil
+	}
+	return keys
+}
+
+// mergeRedactedActivation replaces redacted values with the session keys known to chirpstack. Returns nil, if no activation remains.
+func (c *Controller) mergeRedactedActivation(ctx context.Context, devEui string, activation *api.DeviceActivation, existing *api.DeviceActivation) *api.DeviceActivation {
+	if activation == nil {
+		return nil
+	}
+	if existing == nil {
+		existing = &api.DeviceActivation{}
+	}
+	activation.AppSKey = c.mergeRedactedKey(ctx, devEui, model.DeviceAttributeAppSKey, activation.AppSKey, existing.AppSKey, "")
+	activation.NwkSEncKey = c.mergeRedactedKey(ctx, devEui, model.DeviceAttributeNwkSEncKey, activation.NwkSEncKey, existing.NwkSEncKey, "")
+	activation.SNwkSIntKey = c.mergeRedactedKey(ctx, devEui, model.DeviceAttributeSNwkSIntKey, activation.SNwkSIntKey, existing.SNwkSIntKey, "")
+	activation.FNwkSIntKey = c.mergeRedactedKey(ctx, devEui, model.DeviceAttributeFNwkSIntKey, activation.FNwkSIntKey, existing.FNwkSIntKey, "")
+	if activation.DevAddr == "" && activation.AppSKey == "" && activation.NwkSEncKey == "" && activation.SNwkSIntKey == "" && activation.FNwkSIntKey == "" {
+		return nil
+	}
+	return activation
+}
+
+// mergeRedactedKey prefers the key known to chirpstack. The secret store is only read, if chirpstack lost the key, e.g. because the device was recreated.
+// The secret id is always derived from the dev eui and the attribute key, the attribute value is only a marker, as it is controlled by the device owner.
+func (c *Controller) mergeRedactedKey(ctx context.Context, devEui string, attributeKey string, value string, existing string, fallback string) string {
+	if !isRedactedKey(value) {
+		return value
+	}
+	if existing != "" {
+		return existing
+	}
+	if strings.HasPrefix(value, model.DeviceKeySecretPrefix) && c.secrets != nil {
+		secretId := fmt.Sprintf(model.SecretIdFmtDeviceKey, strings.ToLower(devEui), attributeKey)
+		secret, err := c.secrets.Get(ctx, secretId, model.AttributeOrigin)
+		if err == nil {
+			return string(secret)
+		}
+		log.Logger.Error("unable to read device key from secret store", attributes.ErrorKey, err, "dev_eui", devEui, "secret_id", secretId)
+	}
+	log.Logger.Warn("redacted key is unknown to chirpstack", "dev_eui", devEui)
+	return fallback
+}
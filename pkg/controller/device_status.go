@@ -0,0 +1,215 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/chirpstack/chirpstack/api/go/v4/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetDeviceLoraStatus combines the runtime state of a device known to chirpstack with the information stored by the connector.
+// The token has to grant read access to the platform device.
+func (c *Controller) GetDeviceLoraStatus(ctx context.Context, token jwt.Token, deviceId string) (*model.DeviceLoraStatus, error) {
+	device, err, code := c.deviceRepo.ReadDevice(deviceId, token.Token, models.Read)
+	if err != nil {
+		return nil, deviceRepoHandleErr(err, code)
+	}
+	result := &model.DeviceLoraStatus{
+		DeviceId: device.Id,
+		DevEui:   device.LocalId,
+		Gateways: []model.DeviceLoraGateway{},
+	}
+	for _, a := range device.Attributes {
+		switch a.Key {
+		case model.DeviceAttributeJoinedKey:
+			result.Joined = a.Value == "true"
+		case model.DeviceAttributeDuplicateKey:
+			result.Duplicate = a.Value == "true"
+		}
+	}
+	if result.Duplicate {
+		// the chirpstack device belongs to another tenant, do not expose its state
+		return result, nil
+	}
+
+	appId, err := c.findChirpstackAppIdOfOwner(ctx, device.OwnerId)
+	if err != nil && !errors.Is(err, model.ErrNotFound) {
+		return nil, err
+	}
+	// without tenant or application of the owner, the device is not registered. A chirpstack device with the eui belongs to another tenant.
+	chirpDevice, err := c.chirpDevice.Get(ctx, &api.GetDeviceRequest{
+		DevEui: device.LocalId,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return result, nil
+		}
+		return nil, err
+	}
+	if appId == "" || chirpDevice.Device.ApplicationId != appId {
+		result.Duplicate = true
+		return result, nil
+	}
+	result.Registered = true
+	result.ClassEnabled = chirpDevice.ClassEnabled.String()
+	if chirpDevice.LastSeenAt != nil {
+		lastSeen := chirpDevice.LastSeenAt.AsTime()
+		result.LastSeenAt = &lastSeen
+	}
+	if chirpDevice.DeviceStatus != nil {
+		result.DeviceStatus = &model.DeviceLoraDeviceStatus{
+			Margin:              chirpDevice.DeviceStatus.Margin,
+			ExternalPowerSource: chirpDevice.DeviceStatus.ExternalPowerSource,
+			BatteryLevel:        chirpDevice.DeviceStatus.BatteryLevel,
+		}
+	}
+
+	activation, err := c.chirpDevice.GetActivation(ctx, &api.GetDeviceActivationRequest{
+		DevEui: device.LocalId,
+	})
+	if err != nil && status.Code(err) != codes.NotFound {
+		return nil, err
+	}
+	if activation != nil && activation.DeviceActivation != nil {
+		result.Activation = &model.DeviceLoraActivation{
+			DevAddr:   activation.DeviceActivation.DevAddr,
+			FCntUp:    activation.DeviceActivation.FCntUp,
+			NFCntDown: activation.DeviceActivation.NFCntDown,
+			AFCntDown: activation.DeviceActivation.AFCntDown,
+		}
+	}
+
+	result.Gateways, err = c.listDeviceGateways(ctx, device.LocalId)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// listDeviceGateways returns all gateways that received the device recently, based on the keys written by HandleEvent.
+func (c *Controller) listDeviceGateways(ctx context.Context, localDeviceId string) ([]model.DeviceLoraGateway, error) {
+	gateways := []model.DeviceLoraGateway{}
+	rx := regexp.MustCompile(fmt.Sprintf(model.RedisKeyFmtGatewayDevice, "(.*)", regexp.QuoteMeta(localDeviceId)))
+
+	var cursor uint64
+	for {
+		keys, nextCursor, err := c.rdb.Scan(ctx, cursor, fmt.Sprintf(model.RedisKeyFmtGatewayDevice, "*", localDeviceId), 1000).Result()
+		if err != nil {
+			return gateways, err
+		}
+		for _, key := range keys {
+			matches := rx.FindStringSubmatch(key)
+			if len(matches) != 2 {
+				log.Logger.Error("unexpected key in redis", "key", key)
+				continue
+			}
+			gateway := model.DeviceLoraGateway{GatewayId: matches[1]}
+			value, err := c.rdb.Get(ctx, key).Result()
+			if err != nil {
+				// key might have expired in between
+				continue
+			}
+			ts, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				log.Logger.Error("unable to parse device timestamp in redis", attributes.ErrorKey, err, "key", key)
+			} else {
+				gateway.LastSeenAt = ts
+			}
+			gateways = append(gateways, gateway)
+		}
+		cursor = nextCursor
+		if cursor == 0 {
+			break
+		}
+	}
+	return gateways, nil
+}
+
+// getChirpstackAppIdOfOwner resolves the chirpstack application managed for the given platform user.
+func (c *Controller) getChirpstackAppIdOfOwner(ctx context.Context, ownerId string) (string, error) {
+	c.jwtMux.RLock()
+	user, err := c.gocloakClient.GetUserByID(ctx, c.jwt.AccessToken, c.config.KeycloakRealm, ownerId)
+	c.jwtMux.RUnlock()
+	if err != nil {
+		return "", errors.Join(fmt.Errorf("unable to read user from keycloak"), err)
+	}
+	if user.Email == nil || *user.Email == "" {
+		return "", fmt.Errorf("user has no email")
+	}
+	tenantId, err := c.getOrCreateChirpstackTenantId(ctx, *user.Email, ownerId)
+	if err != nil {
+		return "", err
+	}
+	return c.getOrCreateChirpstackAppId(ctx, tenantId)
+}
+
+// findChirpstackAppIdOfOwner resolves the chirpstack application of the given platform user without creating missing tenants or applications.
+func (c *Controller) findChirpstackAppIdOfOwner(ctx context.Context, ownerId string) (string, error) {
+	c.jwtMux.RLock()
+	user, err := c.gocloakClient.GetUserByID(ctx, c.jwt.AccessToken, c.config.KeycloakRealm, ownerId)
+	c.jwtMux.RUnlock()
+	if err != nil {
+		return "", errors.Join(fmt.Errorf("unable to read user from keycloak"), err)
+	}
+	if user.Email == nil || *user.Email == "" {
+		return "", fmt.Errorf("user has no email")
+	}
+	tenantResp, err := c.chirpTenant.List(ctx, &api.ListTenantsRequest{Search: *user.Email, Limit: 2})
+	if err != nil {
+		return "", err
+	}
+	if len(tenantResp.Result) == 0 {
+		return "", errors.Join(model.ErrNotFound, fmt.Errorf("no chirpstack tenant for owner %s", ownerId))
+	} else if len(tenantResp.Result) > 1 {
+		log.Logger.Error("found multiple tenants", "email", *user.Email)
+		return "", fmt.Errorf("found multiple tenants")
+	}
+	appList, err := c.chirpApp.List(ctx, &api.ListApplicationsRequest{TenantId: tenantResp.Result[0].Id, Search: appName, Limit: 2})
+	if err != nil {
+		return "", err
+	}
+	if len(appList.Result) == 0 {
+		return "", errors.Join(model.ErrNotFound, fmt.Errorf("no chirpstack application for owner %s", ownerId))
+	} else if len(appList.Result) > 1 {
+		log.Logger.Error("found multiple apps", "tenant_id", tenantResp.Result[0].Id)
+		return "", fmt.Errorf("found multiple apps")
+	}
+	return appList.Result[0].Id, nil
+}
+
+func deviceRepoHandleErr(err error, code int) error {
+	switch code {
+	case http.StatusNotFound:
+		return errors.Join(model.ErrNotFound, err)
+	case http.StatusForbidden:
+		return errors.Join(model.ErrForbidden, err)
+	}
+	return err
+}
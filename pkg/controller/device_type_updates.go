@@ -0,0 +1,177 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/models/go/models"
+)
+
+// deviceTypeUpdates funnels device type changes of the event path through a single writer per device type.
+// Changes are merged into a pending device type and written after a debounce delay. Uplinks continue with the
+// last known service id, only uplinks of services without an id wait for the next write.
+type deviceTypeUpdates struct {
+	mux     sync.Mutex
+	pending map[string]*pendingDeviceType
+}
+
+type pendingDeviceType struct {
+	writeMux sync.Mutex
+	base     models.DeviceType // last known device type without pending changes
+	dt       models.DeviceType // last known device type including pending changes
+	samples  []serviceSample   // changes not written yet, reapplied to the current device type on write
+	timer    *time.Timer       // nil, if no write is scheduled
+	next     *deviceTypeWrite  // the write, which will include the pending samples
+}
+
+type serviceSample struct {
+	localServiceId string
+	event          any
+}
+
+type deviceTypeWrite struct {
+	done chan struct{}
+	dt   models.DeviceType
+	err  error
+}
+
+func newDeviceTypeWrite() *deviceTypeWrite {
+	return &deviceTypeWrite{done: make(chan struct{})}
+}
+
+// ensureSyncedService makes sure the device type has an event service matching the event and returns its id.
+// Changes to services with an id are written after a debounce delay, the known id is returned immediately.
+// New services get their id from the platform, so the call waits for the next write. An error is returned, if the write fails.
+func (c *Controller) ensureSyncedService(dt models.DeviceType, localServiceId string, event any) (serviceId string, err error) {
+	u := &c.deviceTypeUpdates
+	u.mux.Lock()
+	pending, ok := u.pending[dt.Id]
+	if ok {
+		dt = pending.dt
+	}
+	base := dt
+	dt, serviceId, changed := c.applyServiceSample(dt, localServiceId, event)
+	if !changed && (serviceId != "" || !ok) {
+		u.mux.Unlock()
+		if serviceId == "" {
+			return "", fmt.Errorf("service %s has no id", localServiceId)
+		}
+		return serviceId, nil
+	}
+	if changed {
+		if !ok {
+			pending = &pendingDeviceType{next: newDeviceTypeWrite(), base: base}
+			u.pending[dt.Id] = pending
+		}
+		pending.dt = dt
+		pending.samples = append(pending.samples, serviceSample{localServiceId: localServiceId, event: event})
+	}
+	if serviceId != "" {
+		if pending.timer == nil {
+			pending.timer = time.AfterFunc(c.config.DeviceTypeUpdateDebounce, func() { c.writeDeviceType(dt.Id, pending) })
+		}
+		u.mux.Unlock()
+		return serviceId, nil
+	}
+
+	// the event can only be handled after the platform assigned a service id, write without delay.
+	// This includes services, which are pending creation by an earlier call.
+	write := pending.next
+	if pending.timer == nil {
+		pending.timer = time.AfterFunc(0, func() { c.writeDeviceType(dt.Id, pending) })
+	} else {
+		pending.timer.Reset(0)
+	}
+	u.mux.Unlock()
+	select {
+	case <-write.done:
+	case <-time.After(time.Minute):
+		return "", fmt.Errorf("timeout waiting for device type update")
+	}
+	if write.err != nil {
+		return "", write.err
+	}
+	for _, s := range write.dt.Services {
+		if s.LocalId == localServiceId && s.ProtocolId == c.config.ProtocolId && s.Interaction == models.EVENT {
+			return s.Id, nil
+		}
+	}
+	return "", fmt.Errorf("service %s not found after update", localServiceId)
+}
+
+// writeDeviceType reapplies the pending samples to the current device type and updates it, if needed.
+// Failed samples are dropped, the next uplink with the same shape schedules them again.
+func (c *Controller) writeDeviceType(deviceTypeId string, pending *pendingDeviceType) {
+	pending.writeMux.Lock()
+	defer pending.writeMux.Unlock()
+	u := &c.deviceTypeUpdates
+	u.mux.Lock()
+	pending.timer = nil
+	samples := pending.samples
+	pending.samples = nil
+	write := pending.next
+	pending.next = newDeviceTypeWrite()
+	current := pending.dt
+	u.mux.Unlock()
+
+	if len(samples) > 0 {
+		write.dt, write.err = c.writeServiceSamples(deviceTypeId, samples)
+		if write.err != nil {
+			log.Logger.Error("unable to update device type", attributes.ErrorKey, write.err, "device_type_id", deviceTypeId)
+		}
+	} else {
+		write.dt = current
+	}
+	close(write.done)
+
+	u.mux.Lock()
+	defer u.mux.Unlock()
+	if write.err == nil {
+		pending.base = write.dt
+	}
+	// failed samples are dropped, so the pending device type is rebuilt from the last known state
+	pending.dt = pending.base
+	for _, sample := range pending.samples {
+		pending.dt, _, _ = c.applyServiceSample(pending.dt, sample.localServiceId, sample.event)
+	}
+	if len(pending.samples) == 0 && pending.timer == nil && u.pending[deviceTypeId] == pending {
+		delete(u.pending, deviceTypeId)
+	}
+}
+
+func (c *Controller) writeServiceSamples(deviceTypeId string, samples []serviceSample) (dt models.DeviceType, err error) {
+	dt, err, _ = c.deviceRepo.ReadDeviceType(deviceTypeId, c.adminToken())
+	if err != nil {
+		return dt, errors.Join(fmt.Errorf("unable to read device type"), err)
+	}
+	changed := false
+	for _, sample := range samples {
+		var sampleChanged bool
+		dt, _, sampleChanged = c.applyServiceSample(dt, sample.localServiceId, sample.event)
+		changed = changed || sampleChanged
+	}
+	if !changed {
+		return dt, nil
+	}
+	return c.updatePlatformDeviceType(dt)
+}
@@ -0,0 +1,232 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	device_repo_model "github.com/SENERGY-Platform/device-repository/lib/model"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/chirpstack/chirpstack/api/go/v4/api"
+	"github.com/chirpstack/chirpstack/api/go/v4/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const euiLength = 8
+const keyLength = 16
+const devAddrLength = 4
+
+const emptyKey = "00000000000000000000000000000000"
+
+var deviceKeyAttributes = []string{
+	model.DeviceAttributeAppKey,
+	model.DeviceAttributeGenAppKey,
+	model.DeviceAttributeNwkKey,
+	model.DeviceAttributeAppSKey,
+	model.DeviceAttributeNwkSEncKey,
+	model.DeviceAttributeSNwkSIntKey,
+	model.DeviceAttributeFNwkSIntKey,
+}
+
+// ValidateDevice checks a proposed platform device before it is saved. If the device has an id, it is validated as an update of the existing device.
+// The token has to grant read access to the device type and write access to an existing device.
+func (c *Controller) ValidateDevice(ctx context.Context, token jwt.Token, device models.Device) (*model.DeviceValidationResult, error) {
+	result := &model.DeviceValidationResult{
+		Errors:   []model.DeviceValidationError{},
+		Warnings: []model.DeviceValidationError{},
+	}
+	ownerId := token.GetUserId()
+	if device.Id != "" {
+		existing, err, code := c.deviceRepo.ReadDevice(device.Id, token.Token, models.Write)
+		if err != nil {
+			return nil, deviceRepoHandleErr(err, code)
+		}
+		ownerId = existing.OwnerId
+	}
+	if device.LocalId != strings.ToLower(device.LocalId) {
+		result.Warnings = append(result.Warnings, model.DeviceValidationError{
+			Field:   model.DeviceValidationFieldLocalId,
+			Message: "dev eui will be converted to lower case",
+		})
+	}
+
+	var deviceType *models.DeviceType
+	if device.DeviceTypeId == "" {
+		result.Errors = append(result.Errors, model.DeviceValidationError{
+			Field:   model.DeviceValidationFieldDeviceTypeId,
+			Message: "device type id must be set",
+		})
+	} else {
+		dt, err, code := c.deviceRepo.ReadDeviceType(device.DeviceTypeId, token.Token)
+		if err != nil && code != http.StatusNotFound {
+			return nil, deviceRepoHandleErr(err, code)
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, model.DeviceValidationError{
+				Field:   model.DeviceValidationFieldDeviceTypeId,
+				Message: "device type does not exist",
+			})
+		} else {
+			deviceType = &dt
+		}
+	}
+
+	// validation must not create tenants or applications. Without application of the owner, every registered eui belongs to another tenant.
+	appId, err := c.findChirpstackAppIdOfOwner(ctx, ownerId)
+	if err != nil && !errors.Is(err, model.ErrNotFound) {
+		return nil, err
+	}
+	validationErrors, err := c.validateDevice(ctx, &device, deviceType, appId)
+	if err != nil {
+		return nil, err
+	}
+	for _, validationError := range validationErrors {
+		if deviceType == nil && validationError.Field == model.DeviceValidationFieldDeviceTypeId {
+			continue // already reported
+		}
+		result.Errors = append(result.Errors, validationError)
+	}
+	result.Valid = len(result.Errors) == 0
+	return result, nil
+}
+
+// validateDevice checks a proposed platform device against the chirpstack device profile referenced by its device type.
+// ownerAppId is the chirpstack application of the device owner, empty if it does not exist yet. A device that already exists in this application is only
+// accepted if the platform device exists as well (device.Id is set).
+// Problems with the device are reported as validation errors, err is only set if the validation itself failed.
+func (c *Controller) validateDevice(ctx context.Context, device *models.Device, deviceType *models.DeviceType, ownerAppId string) (validationErrors []model.DeviceValidationError, err error) {
+	validationErrors = []model.DeviceValidationError{}
+	addErr := func(field string, format string, a ...any) {
+		validationErrors = append(validationErrors, model.DeviceValidationError{Field: field, Message: fmt.Sprintf(format, a...)})
+	}
+
+	devEui := strings.ToLower(device.LocalId)
+	if !isHexOfLength(devEui, euiLength) {
+		addErr(model.DeviceValidationFieldLocalId, "dev eui must be %d hex encoded bytes", euiLength)
+	}
+
+	attributes := map[string]string{}
+	for _, a := range device.Attributes {
+		attributes[a.Key] = strings.ToLower(a.Value)
+	}
+	if joinEui, ok := attributes[model.DeviceAttributeJoinEuiKey]; ok && !isHexOfLength(joinEui, euiLength) {
+		addErr(model.DeviceAttributeJoinEuiKey, "join eui must be %d hex encoded bytes", euiLength)
+	}
+	if devAddr, ok := attributes[model.DeviceAttributeDevAddrKey]; ok && !isHexOfLength(devAddr, devAddrLength) {
+		addErr(model.DeviceAttributeDevAddrKey, "dev addr must be %d hex encoded bytes", devAddrLength)
+	}
+	for _, key := range deviceKeyAttributes {
+		if value, ok := attributes[key]; ok && !isRedactedKey(value) && !isHexOfLength(value, keyLength) {
+			addErr(key, "key must be %d hex encoded bytes", keyLength)
+		}
+	}
+
+	if deviceType == nil || !deviceTypeManagedByLorawanPlatformConnector(*deviceType) {
+		addErr(model.DeviceValidationFieldDeviceTypeId, "device type is not managed by %s", model.DeviceTypeAttributeManagedByValue)
+		return validationErrors, nil
+	}
+	deviceProfileId := getDeviceTypeDeviceProfileId(deviceType)
+	if deviceProfileId == "" {
+		addErr(model.DeviceValidationFieldDeviceTypeId, "device type has no device profile id")
+		return validationErrors, nil
+	}
+	profile, err := c.chirpDeviceProfile.Get(ctx, &api.GetDeviceProfileRequest{Id: deviceProfileId})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			addErr(model.DeviceValidationFieldDeviceTypeId, "device profile %s does not exist in chirpstack", deviceProfileId)
+			return validationErrors, nil
+		}
+		return nil, err
+	}
+
+	hasKey := func(key string) bool {
+		value := attributes[key]
+		return value != "" && value != emptyKey
+	}
+	if profile.DeviceProfile.SupportsOtaa {
+		if !hasKey(model.DeviceAttributeAppKey) && !hasKey(model.DeviceAttributeNwkKey) {
+			addErr(model.DeviceAttributeNwkKey, "device profile requires OTAA, app key or nwk key must be set")
+		}
+	} else {
+		required := []string{model.DeviceAttributeDevAddrKey, model.DeviceAttributeAppSKey, model.DeviceAttributeNwkSEncKey}
+		if profile.DeviceProfile.MacVersion == common.MacVersion_LORAWAN_1_1_0 {
+			required = append(required, model.DeviceAttributeSNwkSIntKey, model.DeviceAttributeFNwkSIntKey)
+		}
+		for _, key := range required {
+			if attributes[key] == "" {
+				addErr(key, "device profile requires ABP, %s must be set", key)
+			}
+		}
+	}
+
+	if !isHexOfLength(devEui, euiLength) {
+		return validationErrors, nil
+	}
+	chirpDevice, err := c.chirpDevice.Get(ctx, &api.GetDeviceRequest{DevEui: devEui})
+	if err != nil && status.Code(err) != codes.NotFound {
+		return nil, err
+	}
+	if chirpDevice != nil && chirpDevice.Device != nil {
+		if chirpDevice.Device.ApplicationId != ownerAppId {
+			addErr(model.DeviceValidationFieldLocalId, "dev eui is already registered in chirpstack by another tenant")
+		} else if device.Id == "" {
+			addErr(model.DeviceValidationFieldLocalId, "dev eui is already registered in chirpstack")
+		}
+	}
+	return validationErrors, nil
+}
+
+// getDeviceTypeOfDeviceProfile returns the managed device type synced from the given device profile or nil, if the profile has not been synced yet.
+func (c *Controller) getDeviceTypeOfDeviceProfile(deviceProfileId string) (*models.DeviceType, error) {
+	c.jwtMux.RLock()
+	deviceTypes, _, err, _ := c.deviceRepo.ListDeviceTypesV3("Bearer "+c.jwt.AccessToken, device_repo_model.DeviceTypeListOptions{
+		AttributeKeys:   []string{model.DeviceTypeAttributeDeviceProfileIdKey},
+		AttributeValues: []string{deviceProfileId},
+	})
+	c.jwtMux.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	for _, deviceType := range deviceTypes {
+		if deviceTypeManagedByLorawanPlatformConnector(deviceType) && getDeviceTypeDeviceProfileId(&deviceType) == deviceProfileId {
+			return &deviceType, nil
+		}
+	}
+	return nil, nil
+}
+
+func getDeviceTypeDeviceProfileId(deviceType *models.DeviceType) string {
+	for _, a := range deviceType.Attributes {
+		if a.Key == model.DeviceTypeAttributeDeviceProfileIdKey {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func isHexOfLength(value string, length int) bool {
+	b, err := hex.DecodeString(value)
+	return err == nil && len(b) == length
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
 	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
 	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
 	platform_connector_lib "github.com/SENERGY-Platform/platform-connector-lib"
 	platform_connector_lib_model "github.com/SENERGY-Platform/platform-connector-lib/model"
 	"github.com/SENERGY-Platform/service-commons/pkg/cache"
@@ -35,16 +36,12 @@ import (
 
 const timeKey = "lora/time"
 
-func (c *Controller) HandleEvent(ctx context.Context, userId string, localDeviceId string, localServiceId string, payload any, ts time.Time, rxInfo []*gw.UplinkRxInfo, deviceProfileId string) error {
-	token, err := c.connector.Security().GetCachedUserToken(userId, platform_connector_lib_model.RemoteInfo{})
+func (c *Controller) HandleEvent(ctx context.Context, userId string, localDeviceId string, localServiceId string, payload any, ts time.Time, rxInfo []*gw.UplinkRxInfo, deviceProfileId string, deduplicationId string) error {
+	device, err := c.getPlatformDevice(userId, localDeviceId)
 	if err != nil {
 		return err
 	}
-	device, err := c.connector.IotCache.GetDeviceByLocalId(token, localDeviceId)
-	if err != nil {
-		return err
-	}
-	deviceType, err := c.connector.IotCache.GetDeviceType(token, device.DeviceTypeId)
+	deviceType, err := c.getPlatformDeviceType(userId, device.DeviceTypeId)
 	if err != nil {
 		return err
 	}
@@ -66,7 +63,7 @@ func (c *Controller) HandleEvent(ctx context.Context, userId string, localDevice
 			}
 		}
 		if !found {
-			expiration, err2 = cache.Use(c.connector.IotCache.GetCache(), "lpc_device_profile_"+deviceProfileId, func() (time.Duration, error) {
+			expiration, err2 = cache.Use(c.cache, "lpc_device_profile_"+deviceProfileId, func() (time.Duration, error) {
 				ctx, cf := context.WithTimeout(context.Background(), time.Second*10)
 				defer cf()
 				profile, err := c.chirpDeviceProfile.Get(ctx, &api.GetDeviceProfileRequest{
@@ -111,19 +108,35 @@ func (c *Controller) HandleEvent(ctx context.Context, userId string, localDevice
 	if err != nil {
 		return err
 	}
-	err = c.connector.HandleDeviceEventWithAuthToken(token, device.Id, serviceId, event, platform_connector_lib.SyncIdempotent)
+	if c.claimDeduplicationId(ctx, localServiceId, deduplicationId) {
+		log.Logger.Debug("suppressed duplicate event", "dev_eui", localDeviceId, "deduplication_id", deduplicationId)
+		return nil
+	}
+	err = c.sink.HandleEvent(ctx, userId, device, models.Service{Id: serviceId, LocalId: localServiceId}, event)
 	if err != nil {
+		c.releaseDeduplicationId(ctx, localServiceId, deduplicationId)
 		return err
 	}
+	eventType := model.WebhookEventUp
+	switch localServiceId {
+	case "status":
+		eventType = model.WebhookEventStatus
+	case model.LocationServiceLocalId:
+		eventType = model.WebhookEventLocation
+	}
+	c.publishWebhookEvent(userId, newWebhookEvent(eventType, device, models.Service{Id: serviceId, LocalId: localServiceId}, ts, encoded, rxInfo))
+	if len(rxInfo) > 0 {
+		// the location event has no rx info, which ends the recursion
+		err = c.estimateDeviceLocation(ctx, userId, device, deviceType, ts, rxInfo, deviceProfileId, deduplicationId)
+		if err != nil {
+			log.Logger.Error("unable to estimate device location", attributes.ErrorKey, err, "dev_eui", localDeviceId)
+		}
+	}
 	return nil
 }
 
-func (c *Controller) AnnotateDeviceJoined(ctx context.Context, userId string, localDeviceId string) error {
-	token, err := c.connector.Security().GetCachedUserToken(userId, platform_connector_lib_model.RemoteInfo{})
-	if err != nil {
-		return err
-	}
-	device, err := c.connector.IotCache.GetDeviceByLocalId(token, localDeviceId)
+func (c *Controller) AnnotateDeviceJoined(ctx context.Context, userId string, localDeviceId string, ts time.Time) error {
+	device, err := c.getPlatformDevice(userId, localDeviceId)
 	if err != nil {
 		return err
 	}
@@ -132,8 +145,13 @@ func (c *Controller) AnnotateDeviceJoined(ctx context.Context, userId string, lo
 		Value:  "true",
 		Origin: model.AttributeOrigin,
 	}, &device)
-	_, err = c.connector.IotCache.UpdateDevice(token, device)
-	return err
+	err = c.updatePlatformDevice(userId, device)
+	if err != nil {
+		return err
+	}
+	c.confirmKeyRotation(ctx, localDeviceId)
+	c.publishWebhookEvent(userId, newWebhookEvent(model.WebhookEventJoin, device, models.Service{}, ts, nil, nil))
+	return nil
 }
 
 func provideEventTime(msg platform_connector_lib.EventMsg) (platform_connector_lib.EventMsg, time.Time) {
@@ -0,0 +1,157 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/configuration"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	platform_connector_lib "github.com/SENERGY-Platform/platform-connector-lib"
+	platform_connector_lib_model "github.com/SENERGY-Platform/platform-connector-lib/model"
+)
+
+// EventSink receives device events after the event service of the device type has been synced.
+type EventSink interface {
+	HandleEvent(ctx context.Context, userId string, device models.Device, service models.Service, event platform_connector_lib.EventMsg) error
+}
+
+// newEventSink creates the configured sink. Without configured sink, events are sent to the platform
+// if kafka is configured and written to stdout otherwise.
+func newEventSink(ctx context.Context, config configuration.Config, connector *platform_connector_lib.Connector) (EventSink, error) {
+	sink := config.EventSink
+	if sink == "" {
+		sink = model.EventSinkFile
+		if connector != nil {
+			sink = model.EventSinkConnector
+		}
+	}
+	switch sink {
+	case model.EventSinkConnector:
+		if connector == nil {
+			return nil, errNoConnector
+		}
+		return &connectorSink{connector: connector}, nil
+	case model.EventSinkFile:
+		if config.EventSinkFile == "" {
+			return &fileSink{w: os.Stdout}, nil
+		}
+		f, err := os.OpenFile(config.EventSinkFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+		sink := &fileSink{w: f}
+		go func() {
+			<-ctx.Done()
+			sink.mux.Lock()
+			defer sink.mux.Unlock()
+			err := f.Close()
+			if err != nil {
+				log.Logger.Error("unable to close event sink file", attributes.ErrorKey, err, "file", config.EventSinkFile)
+			}
+		}()
+		return sink, nil
+	case model.EventSinkWebhook:
+		if config.EventSinkUrl == "" {
+			return nil, fmt.Errorf("event sink webhook requires EVENT_SINK_URL")
+		}
+		return &webhookSink{url: config.EventSinkUrl, client: &http.Client{Timeout: 10 * time.Second}}, nil
+	default:
+		return nil, fmt.Errorf("unknown event sink %s", sink)
+	}
+}
+
+// connectorSink sends events to the platform with the platform-connector-lib.
+type connectorSink struct {
+	connector *platform_connector_lib.Connector
+}
+
+func (sink *connectorSink) HandleEvent(_ context.Context, userId string, device models.Device, service models.Service, event platform_connector_lib.EventMsg) error {
+	token, err := sink.connector.Security().GetCachedUserToken(userId, platform_connector_lib_model.RemoteInfo{})
+	if err != nil {
+		return err
+	}
+	return sink.connector.HandleDeviceEventWithAuthToken(token, device.Id, service.Id, event, platform_connector_lib.SyncIdempotent)
+}
+
+func newSinkEvent(userId string, device models.Device, service models.Service, event platform_connector_lib.EventMsg) model.SinkEvent {
+	return model.SinkEvent{
+		Time:           event[timeKey],
+		UserId:         userId,
+		DeviceId:       device.Id,
+		LocalDeviceId:  device.LocalId,
+		ServiceId:      service.Id,
+		LocalServiceId: service.LocalId,
+		Data:           json.RawMessage(event[model.ProtocolSegmentData]),
+	}
+}
+
+// fileSink writes events as json lines.
+type fileSink struct {
+	mux sync.Mutex
+	w   io.Writer
+}
+
+func (sink *fileSink) HandleEvent(_ context.Context, userId string, device models.Device, service models.Service, event platform_connector_lib.EventMsg) error {
+	b, err := json.Marshal(newSinkEvent(userId, device, service, event))
+	if err != nil {
+		return err
+	}
+	sink.mux.Lock()
+	defer sink.mux.Unlock()
+	_, err = sink.w.Write(append(b, '\n'))
+	return err
+}
+
+// webhookSink posts events as json.
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (sink *webhookSink) HandleEvent(ctx context.Context, userId string, device models.Device, service models.Service, event platform_connector_lib.EventMsg) error {
+	b, err := json.Marshal(newSinkEvent(userId, device, service, event))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sink.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := sink.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode > 299 {
+		temp, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code %d from event sink: %s", resp.StatusCode, string(temp))
+	}
+	return nil
+}
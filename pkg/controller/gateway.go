@@ -18,6 +18,9 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -45,7 +48,7 @@ func (c *Controller) ProvisionGatewayCerts(ctx context.Context, token jwt.Token,
 	if hub.OwnerId != string(token.Sub) {
 		// get user info
 		c.jwtMux.RLock()
-		user, err := c.gocloakClient.GetUserByID(ctx, c.jwt.AccessToken, "master", hub.OwnerId)
+		user, err := c.gocloakClient.GetUserByID(ctx, c.jwt.AccessToken, c.config.KeycloakRealm, hub.OwnerId)
 		c.jwtMux.RUnlock()
 		if err != nil {
 			return certs, err
@@ -84,6 +87,17 @@ func (c *Controller) ProvisionGatewayCerts(ctx context.Context, token jwt.Token,
 		Value:  certResp.ExpiresAt.AsTime().Format(time.RFC3339),
 		Origin: model.AttributeOrigin,
 	}, &hub)
+	if c.secrets != nil {
+		secretId, err := c.storeGatewayCertMetadata(ctx, token, &hub, *eui, certResp)
+		if err != nil {
+			return certs, err
+		}
+		update = model.UpsertGatewayAttribute(models.Attribute{
+			Key:    model.GatewayAttributeCertsSecret,
+			Value:  model.DeviceKeySecretPrefix + secretId,
+			Origin: model.AttributeOrigin,
+		}, &hub) || update // careful: lazy eval!
+	}
 	if update {
 		_, err, code = c.deviceRepo.SetHub(token.Token, hub)
 		if err != nil {
@@ -98,6 +112,24 @@ func (c *Controller) ProvisionGatewayCerts(ctx context.Context, token jwt.Token,
 
 }
 
+// storeGatewayCertMetadata keeps metadata of the issued certificate in the secret store. The private key is not stored.
+func (c *Controller) storeGatewayCertMetadata(ctx context.Context, token jwt.Token, hub *models.Hub, eui string, certResp *api.GenerateGatewayClientCertificateResponse) (secretId string, err error) {
+	fingerprint := sha256.Sum256([]byte(certResp.TlsCert))
+	metadata, err := json.Marshal(model.GatewayCertMetadata{
+		HubId:       hub.Id,
+		GatewayEui:  eui,
+		IssuedAt:    time.Now(),
+		IssuedBy:    token.GetUserId(),
+		ExpiresAt:   certResp.ExpiresAt.AsTime(),
+		Fingerprint: hex.EncodeToString(fingerprint[:]),
+	})
+	if err != nil {
+		return "", err
+	}
+	secretId = fmt.Sprintf(model.SecretIdFmtGatewayCert, eui)
+	return secretId, c.secrets.Put(ctx, secretId, metadata, token.GetUserId())
+}
+
 func provisionGatewayCertsHandleErr(err error, code *int) error {
 	if code != nil {
 		switch *code {
@@ -0,0 +1,262 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/chirpstack/chirpstack/api/go/v4/api"
+	"github.com/chirpstack/chirpstack/api/go/v4/common"
+	"github.com/go-redis/redis/v8"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const keyRotationExpiration = 30 * 24 * time.Hour
+
+// RotateDeviceKeys replaces the root keys of an OTAA device in chirpstack and resets its joined state.
+// If a rejoin is requested, a vendor specific downlink is enqueued and the current session is kept to deliver it.
+// Otherwise, the session is removed immediately and the device has to rejoin on its own. The rotation is pending until the next join.
+// The token has to grant write access to the device.
+func (c *Controller) RotateDeviceKeys(ctx context.Context, token jwt.Token, deviceId string, request model.KeyRotationRequest) (*model.KeyRotationResult, error) {
+	device, err, code := c.deviceRepo.ReadExtendedDevice(deviceId, token.Token, models.Write, true)
+	if err != nil {
+		return nil, deviceRepoHandleErr(err, code)
+	}
+	if device.DeviceType == nil || !deviceTypeManagedByLorawanPlatformConnector(*device.DeviceType) {
+		return nil, errors.Join(model.ErrBadRequest, fmt.Errorf("device is not managed by %s", model.DeviceTypeAttributeManagedByValue))
+	}
+	devEui := strings.ToLower(device.LocalId)
+	profile, err := c.chirpDeviceProfile.Get(ctx, &api.GetDeviceProfileRequest{Id: getDeviceTypeDeviceProfileId(device.DeviceType)})
+	if err != nil {
+		return nil, err
+	}
+	if !profile.DeviceProfile.SupportsOtaa {
+		return nil, errors.Join(model.ErrBadRequest, fmt.Errorf("key rotation requires an OTAA device profile"))
+	}
+
+	var rejoinFPort uint32
+	var rejoinPayload []byte
+	if request.Rejoin {
+		rejoinFPort, rejoinPayload, err = getRejoinDownlink(request, device.DeviceType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &model.KeyRotationResult{}
+	keys := &api.DeviceKeys{DevEui: devEui}
+	keys.NwkKey, err = rotatedKey(request.NwkKey, &result.GeneratedKeys.NwkKey)
+	if err != nil {
+		return nil, err
+	}
+	if profile.DeviceProfile.MacVersion == common.MacVersion_LORAWAN_1_1_0 {
+		keys.AppKey, err = rotatedKey(request.AppKey, &result.GeneratedKeys.AppKey)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		keys.AppKey = emptyKey // LoRaWAN 1.0 devices only use the NwkKey as their AppKey
+	}
+	existingKeys, err := c.chirpDevice.GetKeys(ctx, &api.GetDeviceKeysRequest{DevEui: devEui})
+	if err != nil && status.Code(err) != codes.NotFound {
+		return nil, err
+	}
+	if existingKeys != nil && existingKeys.DeviceKeys != nil {
+		keys.GenAppKey = existingKeys.DeviceKeys.GenAppKey
+	}
+
+	rotation := model.KeyRotation{
+		DeviceId:    device.Id,
+		DevEui:      devEui,
+		Status:      model.KeyRotationStatusPending,
+		RequestedAt: time.Now(),
+		RequestedBy: token.GetUserId(),
+	}
+	if request.Rejoin {
+		// the downlink has to be enqueued before the keys change, as it is delivered within the current session
+		resp, err := c.chirpDevice.Enqueue(ctx, &api.EnqueueDeviceQueueItemRequest{
+			QueueItem: &api.DeviceQueueItem{
+				DevEui: devEui,
+				FPort:  rejoinFPort,
+				Data:   rejoinPayload,
+			},
+		})
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("unable to enqueue rejoin downlink"), err)
+		}
+		rotation.RejoinDownlinkId = resp.Id
+	}
+
+	if existingKeys == nil {
+		_, err = c.chirpDevice.CreateKeys(ctx, &api.CreateDeviceKeysRequest{DeviceKeys: keys})
+	} else {
+		_, err = c.chirpDevice.UpdateKeys(ctx, &api.UpdateDeviceKeysRequest{DeviceKeys: keys})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !request.Rejoin {
+		_, err = c.chirpDevice.Deactivate(ctx, &api.DeactivateDeviceRequest{DevEui: devEui})
+		if err != nil && status.Code(err) != codes.NotFound {
+			return nil, err
+		}
+	}
+
+	model.UpsertDeviceAttribute(models.Attribute{Key: model.DeviceAttributeNwkKey, Value: keys.NwkKey, Origin: model.AttributeOriginWebUI}, &device.Device)
+	if keys.AppKey != emptyKey {
+		model.UpsertDeviceAttribute(models.Attribute{Key: model.DeviceAttributeAppKey, Value: keys.AppKey, Origin: model.AttributeOriginWebUI}, &device.Device)
+	} else {
+		model.RemoveDeviceAttribute(model.DeviceAttributeAppKey, &device.Device)
+	}
+	model.UpsertDeviceAttribute(models.Attribute{Key: model.DeviceAttributeJoinedKey, Value: "false", Origin: model.AttributeOrigin}, &device.Device)
+	_, err = c.redactDeviceKeyAttributes(ctx, &device.Device)
+	if err != nil {
+		return nil, err
+	}
+	err = c.updatePlatformDevice(device.OwnerId, device.Device)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.storeKeyRotation(ctx, rotation)
+	if err != nil {
+		return nil, err
+	}
+	log.Logger.Info("rotated device keys", "device_id", device.Id, "dev_eui", devEui, "rejoin", request.Rejoin, "user", rotation.RequestedBy)
+	result.KeyRotation = rotation
+	return result, nil
+}
+
+// GetDeviceKeyRotation returns the latest key rotation of the device. The token has to grant read access to the device.
+func (c *Controller) GetDeviceKeyRotation(ctx context.Context, token jwt.Token, deviceId string) (*model.KeyRotation, error) {
+	device, err, code := c.deviceRepo.ReadDevice(deviceId, token.Token, models.Read)
+	if err != nil {
+		return nil, deviceRepoHandleErr(err, code)
+	}
+	b, err := c.rdb.Get(ctx, fmt.Sprintf(model.RedisKeyFmtKeyRotation, strings.ToLower(device.LocalId))).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, errors.Join(model.ErrNotFound, fmt.Errorf("no key rotation of device known"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rotation model.KeyRotation
+	err = json.Unmarshal(b, &rotation)
+	if err != nil {
+		return nil, err
+	}
+	if rotation.DeviceId != device.Id {
+		return nil, errors.Join(model.ErrNotFound, fmt.Errorf("no key rotation of device known"))
+	}
+	return &rotation, nil
+}
+
+// confirmKeyRotation marks a pending key rotation of the device as confirmed. Errors are logged only, as they must not fail the join event.
+func (c *Controller) confirmKeyRotation(ctx context.Context, devEui string) {
+	key := fmt.Sprintf(model.RedisKeyFmtKeyRotation, strings.ToLower(devEui))
+	b, err := c.rdb.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return
+	}
+	if err != nil {
+		log.Logger.Error("unable to read key rotation", attributes.ErrorKey, err, "dev_eui", devEui)
+		return
+	}
+	var rotation model.KeyRotation
+	err = json.Unmarshal(b, &rotation)
+	if err != nil {
+		log.Logger.Error("unable to unmarshal key rotation", attributes.ErrorKey, err, "dev_eui", devEui)
+		return
+	}
+	if rotation.Status != model.KeyRotationStatusPending {
+		return
+	}
+	now := time.Now()
+	rotation.Status = model.KeyRotationStatusConfirmed
+	rotation.ConfirmedAt = &now
+	err = c.storeKeyRotation(ctx, rotation)
+	if err != nil {
+		log.Logger.Error("unable to store key rotation", attributes.ErrorKey, err, "dev_eui", devEui)
+		return
+	}
+	log.Logger.Info("key rotation confirmed by join", "device_id", rotation.DeviceId, "dev_eui", devEui)
+}
+
+func (c *Controller) storeKeyRotation(ctx context.Context, rotation model.KeyRotation) error {
+	b, err := json.Marshal(rotation)
+	if err != nil {
+		return err
+	}
+	return c.rdb.Set(ctx, fmt.Sprintf(model.RedisKeyFmtKeyRotation, rotation.DevEui), b, keyRotationExpiration).Err()
+}
+
+// rotatedKey returns the supplied key or generates a new one, which is also written to generated.
+func rotatedKey(supplied string, generated *string) (string, error) {
+	if supplied != "" {
+		supplied = strings.ToLower(supplied)
+		if !isHexOfLength(supplied, keyLength) || supplied == emptyKey {
+			return "", errors.Join(model.ErrBadRequest, fmt.Errorf("keys must be %d hex encoded bytes", keyLength))
+		}
+		return supplied, nil
+	}
+	b := make([]byte, keyLength)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	*generated = hex.EncodeToString(b)
+	return *generated, nil
+}
+
+func getRejoinDownlink(request model.KeyRotationRequest, deviceType *models.DeviceType) (fPort uint32, payload []byte, err error) {
+	fPort = request.RejoinFPort
+	encodedPayload := request.RejoinPayload
+	for _, a := range deviceType.Attributes {
+		switch {
+		case a.Key == model.DeviceTypeAttributeRejoinFPortKey && fPort == 0:
+			port, err := strconv.ParseUint(a.Value, 10, 8)
+			if err != nil {
+				return 0, nil, errors.Join(model.ErrBadRequest, fmt.Errorf("invalid device type attribute %s", model.DeviceTypeAttributeRejoinFPortKey), err)
+			}
+			fPort = uint32(port)
+		case a.Key == model.DeviceTypeAttributeRejoinPayloadKey && encodedPayload == "":
+			encodedPayload = a.Value
+		}
+	}
+	if fPort == 0 || fPort > 223 || encodedPayload == "" {
+		return 0, nil, errors.Join(model.ErrBadRequest, fmt.Errorf("rejoin requires an f port (1-223) and a payload, either in the request or as device type attributes"))
+	}
+	payload, err = hex.DecodeString(encodedPayload)
+	if err != nil {
+		return 0, nil, errors.Join(model.ErrBadRequest, fmt.Errorf("rejoin payload must be hex encoded"), err)
+	}
+	return fPort, payload, nil
+}
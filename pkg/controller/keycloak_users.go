@@ -0,0 +1,114 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/go-redis/redis/v8"
+)
+
+const keycloakUsersPageSize = 500
+const keycloakUsersSnapshotAttempts = 3
+
+var errIncompleteUserListing = errors.New("keycloak user listing is incomplete")
+
+// listKeycloakUsers pages through all users of the realm. The listing is repeated if the user count changed while paging,
+// so that a concurrently created or deleted user cannot shift pages. complete is false if no stable snapshot could be taken.
+func (c *Controller) listKeycloakUsers(ctx context.Context) (users []*gocloak.User, complete bool, err error) {
+	for range keycloakUsersSnapshotAttempts {
+		c.jwtMux.RLock()
+		before, err := c.gocloakClient.GetUserCount(ctx, c.jwt.AccessToken, c.config.KeycloakRealm, gocloak.GetUsersParams{})
+		c.jwtMux.RUnlock()
+		if err != nil {
+			return nil, false, err
+		}
+		users = []*gocloak.User{}
+		seen := map[string]bool{}
+		first := 0
+		for {
+			c.jwtMux.RLock()
+			page, err := c.gocloakClient.GetUsers(ctx, c.jwt.AccessToken, c.config.KeycloakRealm, gocloak.GetUsersParams{
+				First: gocloak.IntP(first),
+				Max:   gocloak.IntP(keycloakUsersPageSize),
+			})
+			c.jwtMux.RUnlock()
+			if err != nil {
+				return nil, false, err
+			}
+			for _, user := range page {
+				if user == nil || user.ID == nil || seen[*user.ID] {
+					continue
+				}
+				seen[*user.ID] = true
+				users = append(users, user)
+			}
+			if len(page) < keycloakUsersPageSize {
+				break
+			}
+			first += keycloakUsersPageSize
+		}
+		c.jwtMux.RLock()
+		after, err := c.gocloakClient.GetUserCount(ctx, c.jwt.AccessToken, c.config.KeycloakRealm, gocloak.GetUsersParams{})
+		c.jwtMux.RUnlock()
+		if err != nil {
+			return nil, false, err
+		}
+		if before == after && len(users) == after {
+			return users, true, nil
+		}
+		log.Logger.Warn("keycloak user count changed while listing users, retrying", "before", before, "after", after, "listed", len(users))
+	}
+	return users, false, nil
+}
+
+// listKeycloakUsersForDeletion returns all users only if the listing is safe to derive deletions from.
+// Deletions are refused if the listing is incomplete or if the user count shrank by more than the configured percentage since the last accepted run.
+// To accept a legitimate sharp decrease, delete the redis key model.RedisKeyKeycloakUserCount.
+func (c *Controller) listKeycloakUsersForDeletion(ctx context.Context) ([]*gocloak.User, error) {
+	users, complete, err := c.listKeycloakUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !complete || len(users) == 0 {
+		return nil, errors.Join(errIncompleteUserListing, fmt.Errorf("refusing to delete, listed %d users", len(users)))
+	}
+	previous, err := c.rdb.Get(ctx, model.RedisKeyKeycloakUserCount).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	if previous != "" {
+		previousCount, err := strconv.Atoi(previous)
+		if err != nil {
+			return nil, err
+		}
+		if len(users)*100 < previousCount*(100-int(min(c.config.UserDeletionMaxShrink, 100))) {
+			return nil, errors.Join(errIncompleteUserListing, fmt.Errorf("refusing to delete, keycloak user count shrank from %d to %d", previousCount, len(users)))
+		}
+	}
+	err = c.rdb.Set(ctx, model.RedisKeyKeycloakUserCount, len(users), 0).Err()
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
@@ -0,0 +1,271 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/SENERGY-Platform/service-commons/pkg/cache"
+	"github.com/chirpstack/chirpstack/api/go/v4/api"
+	"github.com/chirpstack/chirpstack/api/go/v4/gw"
+)
+
+// distances are derived from the rssi with a log-distance path loss model, which is only a rough approximation for lora links
+const locationReferenceRssi = -40.0 // dBm at 1 m
+const locationPathLossExponent = 2.2
+const earthRadius = 6371000.0 // meters
+
+type gatewayLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type gatewayReception struct {
+	location gatewayLocation
+	rssi     int32
+}
+
+// estimateDeviceLocation publishes the position estimated from the receiving gateways as location service, if the device type has a location estimator.
+// The estimate is received like an uplink, so it is buffered and retried if the uplink buffer is enabled.
+// The device location attributes are updated, if the estimate moved more than the update distance of the device type.
+func (c *Controller) estimateDeviceLocation(ctx context.Context, userId string, device models.Device, deviceType models.DeviceType, ts time.Time, rxInfo []*gw.UplinkRxInfo, deviceProfileId string, deduplicationId string) error {
+	estimator, updateDistance := locationSettingsOf(deviceType)
+	if estimator == "" {
+		return nil
+	}
+	receptions := c.gatewayReceptions(rxInfo)
+	if len(receptions) == 0 {
+		log.Logger.Debug("no receiving gateway with known location", "dev_eui", device.LocalId)
+		return nil
+	}
+	estimate := estimateLocation(estimator, receptions)
+	err := c.ReceiveEvent(ctx, userId, device.LocalId, model.LocationServiceLocalId, estimate, ts, nil, deviceProfileId, deduplicationId)
+	if err != nil {
+		return err
+	}
+	if updateDistance > 0 {
+		return c.updateDeviceLocation(userId, device, estimate, updateDistance)
+	}
+	return nil
+}
+
+// locationSettingsOf reads the location settings of the device type. Invalid values are ignored, the device profile tags are validated by prepareDeviceType.
+func locationSettingsOf(deviceType models.DeviceType) (estimator string, updateDistance float64) {
+	for _, a := range deviceType.Attributes {
+		switch a.Key {
+		case model.DeviceTypeAttributeLocationEstimatorKey:
+			if validLocationEstimator(a.Value) {
+				estimator = a.Value
+			}
+		case model.DeviceTypeAttributeLocationUpdateDistanceKey:
+			if d, err := strconv.ParseFloat(a.Value, 64); err == nil {
+				updateDistance = d
+			}
+		}
+	}
+	return estimator, updateDistance
+}
+
+func validLocationEstimator(estimator string) bool {
+	return estimator == model.LocationEstimatorCentroid || estimator == model.LocationEstimatorMultilateration
+}
+
+// gatewayReceptions returns the strongest reception per gateway with known location. The location reported with the uplink is preferred
+// over the location of the chirpstack gateway, which is synced from the hub attributes.
+func (c *Controller) gatewayReceptions(rxInfo []*gw.UplinkRxInfo) []gatewayReception {
+	receptions := []gatewayReception{}
+	index := map[string]int{}
+	for _, rx := range rxInfo {
+		if i, ok := index[rx.GatewayId]; ok {
+			receptions[i].rssi = max(receptions[i].rssi, rx.Rssi)
+			continue
+		}
+		var location gatewayLocation
+		if rx.Location != nil && (rx.Location.Latitude != 0 || rx.Location.Longitude != 0) {
+			location = gatewayLocation{Latitude: rx.Location.Latitude, Longitude: rx.Location.Longitude}
+		} else {
+			var err error
+			location, err = c.getGatewayLocation(rx.GatewayId)
+			if err != nil {
+				log.Logger.Warn("unable to get gateway location", attributes.ErrorKey, err, "gateway_id", rx.GatewayId)
+				continue
+			}
+		}
+		if location.Latitude == 0 && location.Longitude == 0 {
+			continue
+		}
+		index[rx.GatewayId] = len(receptions)
+		receptions = append(receptions, gatewayReception{location: location, rssi: rx.Rssi})
+	}
+	return receptions
+}
+
+func (c *Controller) getGatewayLocation(gatewayId string) (gatewayLocation, error) {
+	return cache.Use(c.cache, "lpc_gateway_location_"+gatewayId, func() (gatewayLocation, error) {
+		ctx, cf := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cf()
+		resp, err := c.chirpGateway.Get(ctx, &api.GetGatewayRequest{GatewayId: gatewayId})
+		if err != nil {
+			return gatewayLocation{}, err
+		}
+		if resp.Gateway.Location == nil {
+			return gatewayLocation{}, nil
+		}
+		return gatewayLocation{Latitude: resp.Gateway.Location.Latitude, Longitude: resp.Gateway.Location.Longitude}, nil
+	}, nil, 10*time.Minute)
+}
+
+// updateDeviceLocation sets the location attributes of the device. Attributes maintained by others, e.g. in the web ui, are never overwritten.
+func (c *Controller) updateDeviceLocation(userId string, device models.Device, estimate model.LocationEstimate, updateDistance float64) error {
+	var lat, lon string
+	for _, a := range device.Attributes {
+		if a.Key != model.DeviceAttributeLat && a.Key != model.DeviceAttributeLon {
+			continue
+		}
+		if a.Origin != model.AttributeOrigin {
+			return nil
+		}
+		if a.Key == model.DeviceAttributeLat {
+			lat = a.Value
+		} else {
+			lon = a.Value
+		}
+	}
+	latitude, err1 := strconv.ParseFloat(lat, 64)
+	longitude, err2 := strconv.ParseFloat(lon, 64)
+	if err1 == nil && err2 == nil && haversine(gatewayLocation{Latitude: latitude, Longitude: longitude}, gatewayLocation{Latitude: estimate.Latitude, Longitude: estimate.Longitude}) < updateDistance {
+		return nil
+	}
+	model.UpsertDeviceAttribute(models.Attribute{
+		Key:    model.DeviceAttributeLat,
+		Value:  strconv.FormatFloat(estimate.Latitude, 'f', 6, 64),
+		Origin: model.AttributeOrigin,
+	}, &device)
+	model.UpsertDeviceAttribute(models.Attribute{
+		Key:    model.DeviceAttributeLon,
+		Value:  strconv.FormatFloat(estimate.Longitude, 'f', 6, 64),
+		Origin: model.AttributeOrigin,
+	}, &device)
+	return c.updatePlatformDevice(userId, device)
+}
+
+// estimateLocation works in a local plane around the first gateway, which is accurate enough for the range of lora gateways.
+func estimateLocation(estimator string, receptions []gatewayReception) model.LocationEstimate {
+	origin := receptions[0].location
+	points := make([][2]float64, len(receptions))
+	distances := make([]float64, len(receptions))
+	for i, reception := range receptions {
+		points[i] = toPlane(origin, reception.location)
+		distances[i] = rssiDistance(reception.rssi)
+	}
+	method := model.LocationEstimatorCentroid
+	position, accuracy := weightedCentroid(points, distances)
+	if estimator == model.LocationEstimatorMultilateration && len(receptions) >= 3 {
+		if p, a, ok := multilaterate(points, distances); ok {
+			method = model.LocationEstimatorMultilateration
+			position, accuracy = p, a
+		}
+	}
+	location := fromPlane(origin, position)
+	return model.LocationEstimate{
+		Latitude:  location.Latitude,
+		Longitude: location.Longitude,
+		Accuracy:  math.Round(accuracy),
+		Method:    method,
+		Gateways:  len(receptions),
+	}
+}
+
+func rssiDistance(rssi int32) float64 {
+	return max(math.Pow(10, (locationReferenceRssi-float64(rssi))/(10*locationPathLossExponent)), 1)
+}
+
+// weightedCentroid weights the gateways by the inverse of their estimated distance. The accuracy is the weighted mean distance.
+func weightedCentroid(points [][2]float64, distances []float64) (position [2]float64, accuracy float64) {
+	var sum float64
+	for i, p := range points {
+		w := 1 / distances[i]
+		position[0] += w * p[0]
+		position[1] += w * p[1]
+		accuracy += w * distances[i]
+		sum += w
+	}
+	return [2]float64{position[0] / sum, position[1] / sum}, accuracy / sum
+}
+
+// multilaterate solves the linearized distance equations with least squares. The accuracy is the rms of the distance residuals.
+// Fails for (nearly) collinear gateways and for positions far outside the estimated ranges.
+func multilaterate(points [][2]float64, distances []float64) (position [2]float64, accuracy float64, ok bool) {
+	n := len(points) - 1
+	var s11, s12, s22, t1, t2 float64
+	for i := 0; i < n; i++ {
+		a1 := 2 * (points[n][0] - points[i][0])
+		a2 := 2 * (points[n][1] - points[i][1])
+		b := distances[i]*distances[i] - distances[n]*distances[n] -
+			points[i][0]*points[i][0] + points[n][0]*points[n][0] -
+			points[i][1]*points[i][1] + points[n][1]*points[n][1]
+		s11 += a1 * a1
+		s12 += a1 * a2
+		s22 += a2 * a2
+		t1 += a1 * b
+		t2 += a2 * b
+	}
+	// the determinant is the product of the eigenvalues, the trace their sum. Comparing both is independent of the orientation of the gateways.
+	det := s11*s22 - s12*s12
+	if det <= 1e-9*(s11+s22)*(s11+s22) {
+		return position, 0, false
+	}
+	position = [2]float64{(s22*t1 - s12*t2) / det, (s11*t2 - s12*t1) / det}
+	var sum, maxDistance float64
+	for i, p := range points {
+		d := math.Hypot(position[0]-p[0], position[1]-p[1])
+		if d > 2*distances[i]+1000 {
+			return position, 0, false
+		}
+		sum += (d - distances[i]) * (d - distances[i])
+		maxDistance = max(maxDistance, distances[i])
+	}
+	return position, min(math.Sqrt(sum/float64(len(points))), maxDistance), true
+}
+
+func toPlane(origin gatewayLocation, location gatewayLocation) [2]float64 {
+	return [2]float64{
+		(location.Longitude - origin.Longitude) * math.Pi / 180 * earthRadius * math.Cos(origin.Latitude*math.Pi/180),
+		(location.Latitude - origin.Latitude) * math.Pi / 180 * earthRadius,
+	}
+}
+
+func fromPlane(origin gatewayLocation, p [2]float64) gatewayLocation {
+	return gatewayLocation{
+		Latitude:  origin.Latitude + p[1]/earthRadius*180/math.Pi,
+		Longitude: origin.Longitude + p[0]/(earthRadius*math.Cos(origin.Latitude*math.Pi/180))*180/math.Pi,
+	}
+}
+
+func haversine(a gatewayLocation, b gatewayLocation) float64 {
+	dLat := (b.Latitude - a.Latitude) * math.Pi / 180
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(a.Latitude*math.Pi/180)*math.Cos(b.Latitude*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,221 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"math"
+	"testing"
+
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+)
+
+// testOrigin is the reference point of the test gateways, positions are given in meters east and north of it
+var testOrigin = gatewayLocation{Latitude: 51.34, Longitude: 12.37}
+
+func testReception(east float64, north float64, rssi int32) gatewayReception {
+	return gatewayReception{location: fromPlane(testOrigin, [2]float64{east, north}), rssi: rssi}
+}
+
+func TestRssiDistance(t *testing.T) {
+	tests := []struct {
+		rssi     int32
+		expected float64
+	}{
+		{rssi: -40, expected: 1},
+		{rssi: -30, expected: 1}, // closer than the reference distance is clamped
+		{rssi: -84, expected: 100},
+		{rssi: -106, expected: 1000},
+	}
+	for _, test := range tests {
+		if actual := rssiDistance(test.rssi); math.Abs(actual-test.expected) > 1e-6 {
+			t.Errorf("rssi %d: got %f, expected %f", test.rssi, actual, test.expected)
+		}
+	}
+}
+
+func TestHaversine(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        gatewayLocation
+		b        gatewayLocation
+		expected float64
+	}{
+		{name: "same point", a: testOrigin, b: testOrigin, expected: 0},
+		{name: "one degree latitude", a: gatewayLocation{Latitude: 0, Longitude: 0}, b: gatewayLocation{Latitude: 1, Longitude: 0}, expected: earthRadius * math.Pi / 180},
+		{name: "one degree longitude at the equator", a: gatewayLocation{Latitude: 0, Longitude: 0}, b: gatewayLocation{Latitude: 0, Longitude: 1}, expected: earthRadius * math.Pi / 180},
+		{name: "local plane", a: testOrigin, b: fromPlane(testOrigin, [2]float64{3000, 4000}), expected: 5000},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := haversine(test.a, test.b); math.Abs(actual-test.expected) > 1 {
+				t.Errorf("got %f, expected %f", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestWeightedCentroid(t *testing.T) {
+	tests := []struct {
+		name             string
+		points           [][2]float64
+		distances        []float64
+		expected         [2]float64
+		expectedAccuracy float64
+	}{
+		{name: "single gateway", points: [][2]float64{{100, 200}}, distances: []float64{500}, expected: [2]float64{100, 200}, expectedAccuracy: 500},
+		{name: "equal distances", points: [][2]float64{{0, 0}, {1000, 0}}, distances: []float64{400, 400}, expected: [2]float64{500, 0}, expectedAccuracy: 400},
+		{name: "closer gateway weighs more", points: [][2]float64{{0, 0}, {900, 0}}, distances: []float64{100, 200}, expected: [2]float64{300, 0}, expectedAccuracy: 400.0 / 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			position, accuracy := weightedCentroid(test.points, test.distances)
+			if math.Hypot(position[0]-test.expected[0], position[1]-test.expected[1]) > 1e-6 || math.Abs(accuracy-test.expectedAccuracy) > 1e-6 {
+				t.Errorf("got %v ± %f, expected %v ± %f", position, accuracy, test.expected, test.expectedAccuracy)
+			}
+		})
+	}
+}
+
+func TestMultilaterate(t *testing.T) {
+	triangle := [][2]float64{{0, 0}, {1000, 0}, {0, 1000}}
+	tests := []struct {
+		name             string
+		points           [][2]float64
+		distances        []float64
+		ok               bool
+		expected         [2]float64
+		expectedAccuracy float64
+	}{
+		{
+			name:      "exact distances",
+			points:    triangle,
+			distances: []float64{500, math.Hypot(700, 400), math.Hypot(300, 600)},
+			ok:        true,
+			expected:  [2]float64{300, 400},
+		},
+		{
+			name:      "four gateways",
+			points:    [][2]float64{{0, 0}, {1000, 0}, {0, 1000}, {1000, 1000}},
+			distances: []float64{math.Hypot(200, 700), math.Hypot(800, 700), math.Hypot(200, 300), math.Hypot(800, 300)},
+			ok:        true,
+			expected:  [2]float64{200, 700},
+		},
+		{
+			name:             "accuracy is limited by the largest distance",
+			points:           triangle,
+			distances:        []float64{10, 10, 10},
+			ok:               true,
+			expected:         [2]float64{500, 500},
+			expectedAccuracy: 10,
+		},
+		{name: "collinear gateways", points: [][2]float64{{0, 0}, {1000, 0}, {2000, 0}}, distances: []float64{500, 500, 1500}},
+		{name: "diagonal collinear gateways", points: [][2]float64{{0, 0}, {1000, 1000}, {2000, 2000}}, distances: []float64{500, 900, 2300}},
+		{name: "nearly collinear gateways", points: [][2]float64{{0, 0}, {1000, 0}, {2000, 1e-6}}, distances: []float64{500, 500, 1500}},
+		{name: "far outlier", points: triangle, distances: []float64{100, 100, 5000}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			position, accuracy, ok := multilaterate(test.points, test.distances)
+			if ok != test.ok {
+				t.Fatalf("got ok=%v at %v, expected ok=%v", ok, position, test.ok)
+			}
+			if !ok {
+				return
+			}
+			if math.Hypot(position[0]-test.expected[0], position[1]-test.expected[1]) > 1e-3 || math.Abs(accuracy-test.expectedAccuracy) > 1e-3 {
+				t.Errorf("got %v ± %f, expected %v ± %f", position, accuracy, test.expected, test.expectedAccuracy)
+			}
+		})
+	}
+}
+
+func TestEstimateLocation(t *testing.T) {
+	tests := []struct {
+		name       string
+		estimator  string
+		receptions []gatewayReception
+		method     string
+		expected   gatewayLocation
+		tolerance  float64 // meters
+	}{
+		{
+			name:       "single gateway",
+			estimator:  model.LocationEstimatorCentroid,
+			receptions: []gatewayReception{testReception(0, 0, -84)},
+			method:     model.LocationEstimatorCentroid,
+			expected:   testOrigin,
+			tolerance:  1,
+		},
+		{
+			name:       "centroid",
+			estimator:  model.LocationEstimatorCentroid,
+			receptions: []gatewayReception{testReception(-1000, 0, -106), testReception(1000, 0, -106), testReception(0, 2000, -106)},
+			method:     model.LocationEstimatorCentroid,
+			expected:   fromPlane(testOrigin, [2]float64{0, 2000.0 / 3}),
+			tolerance:  1,
+		},
+		{
+			name:       "multilateration with three gateways",
+			estimator:  model.LocationEstimatorMultilateration,
+			receptions: []gatewayReception{testReception(0, 1000, -106), testReception(1000, 0, -106), testReception(-600, -800, -106)},
+			method:     model.LocationEstimatorMultilateration,
+			expected:   testOrigin,
+			tolerance:  5,
+		},
+		{
+			name:       "multilateration falls back to the centroid with two gateways",
+			estimator:  model.LocationEstimatorMultilateration,
+			receptions: []gatewayReception{testReception(-1000, 0, -106), testReception(1000, 0, -106)},
+			method:     model.LocationEstimatorCentroid,
+			expected:   testOrigin,
+			tolerance:  1,
+		},
+		{
+			name:       "multilateration falls back to the centroid with collinear gateways",
+			estimator:  model.LocationEstimatorMultilateration,
+			receptions: []gatewayReception{testReception(-1000, 0, -106), testReception(0, 0, -84), testReception(1000, 0, -106)},
+			method:     model.LocationEstimatorCentroid,
+			expected:   testOrigin,
+			tolerance:  1,
+		},
+		{
+			name:       "multilateration falls back to the centroid for far outliers",
+			estimator:  model.LocationEstimatorMultilateration,
+			receptions: []gatewayReception{testReception(0, 0, -84), testReception(1000, 0, -84), testReception(0, 1000, -125)},
+			method:     model.LocationEstimatorCentroid,
+			expected:   fromPlane(testOrigin, [2]float64{1000 * 0.01 / (0.02 + 1/rssiDistance(-125)), 1000 * (1 / rssiDistance(-125)) / (0.02 + 1/rssiDistance(-125))}),
+			tolerance:  1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			estimate := estimateLocation(test.estimator, test.receptions)
+			if estimate.Method != test.method {
+				t.Errorf("got method %s, expected %s", estimate.Method, test.method)
+			}
+			if estimate.Gateways != len(test.receptions) {
+				t.Errorf("got %d gateways, expected %d", estimate.Gateways, len(test.receptions))
+			}
+			if d := haversine(gatewayLocation{Latitude: estimate.Latitude, Longitude: estimate.Longitude}, test.expected); d > test.tolerance {
+				t.Errorf("estimate is %f m off, got %f,%f", d, estimate.Latitude, estimate.Longitude)
+			}
+			if estimate.Accuracy < 0 {
+				t.Errorf("negative accuracy %f", estimate.Accuracy)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/cache"
+	"github.com/chirpstack/chirpstack/api/go/v4/api"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// setupMqtt connects to the broker of the chirpstack mqtt integration. Device events are subscribed in ingestion mode "mqtt".
+func (c *Controller) setupMqtt(ctx context.Context) error {
+	clientId := c.config.MqttClientId
+	if clientId == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return err
+		}
+		clientId = "lorawan-platform-connector-" + hostname
+	}
+	options := paho.NewClientOptions().
+		AddBroker(c.config.MqttBroker).
+		SetClientID(clientId).
+		SetUsername(c.config.MqttUsername).
+		SetPassword(c.config.MqttPassword).
+		SetCleanSession(false).
+		SetAutoReconnect(true).
+		SetOrderMatters(false).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			log.Logger.Warn("lost connection to mqtt broker", attributes.ErrorKey, err)
+		}).
+		SetOnConnectHandler(func(client paho.Client) {
+			if c.config.IngestionMode != model.IngestionModeMqtt {
+				return
+			}
+			// subscribe again after reconnects, in case the broker did not keep the session
+			token := client.Subscribe(c.config.MqttEventTopic, 1, c.handleMqttEvent)
+			if token.WaitTimeout(10*time.Second) && token.Error() == nil {
+				log.Logger.Info("subscribed to chirpstack events", "topic", c.config.MqttEventTopic)
+				return
+			}
+			log.Logger.Error("unable to subscribe to chirpstack events", attributes.ErrorKey, token.Error(), "topic", c.config.MqttEventTopic)
+		})
+	client := paho.NewClient(options)
+	token := client.Connect()
+	if !token.WaitTimeout(10 * time.Second) {
+		return fmt.Errorf("timeout connecting to mqtt broker %s", c.config.MqttBroker)
+	}
+	if token.Error() != nil {
+		return errors.Join(fmt.Errorf("unable to connect to mqtt broker %s", c.config.MqttBroker), token.Error())
+	}
+	c.mqtt = client
+	go func() {
+		<-ctx.Done()
+		client.Disconnect(250)
+	}()
+	return nil
+}
+
+// handleMqttEvent handles messages of the topic application/<application id>/device/<dev eui>/event/<event type>.
+// Events are ignored, if the application or device of the topic does not match the device info of the payload.
+func (c *Controller) handleMqttEvent(_ paho.Client, message paho.Message) {
+	applicationId, devEui, eventType, err := parseMqttEventTopic(message.Topic())
+	if err != nil {
+		log.Logger.Error("ignoring chirpstack event", attributes.ErrorKey, err, "topic", message.Topic())
+		return
+	}
+	event, err := unmarshalChirpstackEvent(eventType, c.config.MqttEncoding, message.Payload())
+	if err != nil {
+		log.Logger.Error("unable to handle chirpstack event", attributes.ErrorKey, err, "topic", message.Topic())
+		return
+	}
+	if event == nil {
+		return
+	}
+	deviceInfo := event.GetDeviceInfo()
+	if deviceInfo != nil && (deviceInfo.ApplicationId != applicationId || strings.ToLower(deviceInfo.DevEui) != devEui) {
+		log.Logger.Error("ignoring chirpstack event of other device than in topic", "topic", message.Topic(), "application_id", deviceInfo.ApplicationId, "dev_eui", deviceInfo.DevEui)
+		return
+	}
+	ctx, cf := context.WithTimeout(context.Background(), time.Minute)
+	defer cf()
+	err = c.dispatchChirpstackEvent(ctx, event)
+	if err != nil {
+		log.Logger.Error("unable to handle chirpstack event", attributes.ErrorKey, err, "topic", message.Topic())
+	}
+}
+
+// parseMqttEventTopic splits a topic of the form application/<application id>/device/<dev eui>/event/<event type>.
+// Prefixes of shared subscriptions are not part of the topic of received messages.
+func parseMqttEventTopic(topic string) (applicationId string, devEui string, eventType string, err error) {
+	parts := strings.Split(topic, "/")
+	if len(parts) != 6 || parts[0] != "application" || parts[2] != "device" || parts[4] != "event" || parts[1] == "" || parts[3] == "" || parts[5] == "" {
+		return "", "", "", fmt.Errorf("unexpected event topic %s", topic)
+	}
+	return parts[1], strings.ToLower(parts[3]), parts[5], nil
+}
+
+// enqueueDownlink enqueues the item with the chirpstack api or publishes it to the mqtt integration, if MqttDownlinks is enabled.
+func (c *Controller) enqueueDownlink(ctx context.Context, item *api.DeviceQueueItem) (id string, err error) {
+	if !c.config.MqttDownlinks {
+		resp, err := c.chirpDevice.Enqueue(ctx, &api.EnqueueDeviceQueueItemRequest{QueueItem: item})
+		if err != nil {
+			return "", err
+		}
+		return resp.Id, nil
+	}
+	applicationId, err := cache.Use(c.cache, "lpc_device_application_"+item.DevEui, func() (string, error) {
+		device, err := c.chirpDevice.Get(ctx, &api.GetDeviceRequest{DevEui: item.DevEui})
+		if err != nil {
+			return "", err
+		}
+		return device.Device.ApplicationId, nil
+	}, nil, time.Minute)
+	if err != nil {
+		return "", err
+	}
+	// the queue item id is not returned by the mqtt integration, so it is set in advance
+	item.Id = uuid.NewString()
+	var payload []byte
+	switch c.config.MqttEncoding {
+	case model.EventEncodingProtobuf:
+		payload, err = proto.Marshal(item)
+	case model.EventEncodingJson:
+		payload, err = protojson.Marshal(item)
+	default:
+		err = fmt.Errorf("unsupported encoding %s", c.config.MqttEncoding)
+	}
+	if err != nil {
+		return "", err
+	}
+	token := c.mqtt.Publish(fmt.Sprintf("application/%s/device/%s/command/down", applicationId, item.DevEui), 1, false, payload)
+	select {
+	case <-token.Done():
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	if token.Error() != nil {
+		return "", token.Error()
+	}
+	return item.Id, nil
+}
@@ -0,0 +1,339 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/configuration"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/cache"
+	"github.com/chirpstack/chirpstack/api/go/v4/api"
+	"github.com/chirpstack/chirpstack/api/go/v4/integration"
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestParseMqttEventTopic(t *testing.T) {
+	tests := []struct {
+		name          string
+		topic         string
+		applicationId string
+		devEui        string
+		eventType     string
+		err           bool
+	}{
+		{name: "uplink", topic: "application/app-1/device/0102030405060708/event/up", applicationId: "app-1", devEui: "0102030405060708", eventType: "up"},
+		{name: "join", topic: "application/app-1/device/0102030405060708/event/join", applicationId: "app-1", devEui: "0102030405060708", eventType: "join"},
+		{name: "upper case eui", topic: "application/app-1/device/A1B2C3D4E5F60708/event/status", applicationId: "app-1", devEui: "a1b2c3d4e5f60708", eventType: "status"},
+		{name: "command topic", topic: "application/app-1/device/0102030405060708/command/down", err: true},
+		{name: "missing event type", topic: "application/app-1/device/0102030405060708/event/", err: true},
+		{name: "missing dev eui", topic: "application/app-1/device//event/up", err: true},
+		{name: "too short", topic: "application/app-1/device/0102030405060708", err: true},
+		{name: "too long", topic: "application/app-1/device/0102030405060708/event/up/extra", err: true},
+		{name: "wrong prefix", topic: "gateway/0102030405060708/event/up", err: true},
+		{name: "empty", topic: "", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			applicationId, devEui, eventType, err := parseMqttEventTopic(test.topic)
+			if test.err {
+				if err == nil {
+					t.Fatalf("expected error, got %s %s %s", applicationId, devEui, eventType)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if applicationId != test.applicationId || devEui != test.devEui || eventType != test.eventType {
+				t.Fatalf("got %s %s %s, expected %s %s %s", applicationId, devEui, eventType, test.applicationId, test.devEui, test.eventType)
+			}
+		})
+	}
+}
+
+func TestUnmarshalEvent(t *testing.T) {
+	object, err := structpb.NewStruct(map[string]any{"temperature": 21.5, "valve": "open"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &integration.UplinkEvent{
+		DeduplicationId: "d5a3e2b4-6a5f-4bd0-9d8b-7b4b3d4e5f60",
+		DeviceInfo: &integration.DeviceInfo{
+			TenantId:        "tenant-1",
+			ApplicationId:   "app-1",
+			DeviceProfileId: "profile-1",
+			DevEui:          "0102030405060708",
+		},
+		FPort:  2,
+		Object: object,
+	}
+	protobufBody, err := proto.Marshal(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonBody, err := protojson.Marshal(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+		err      bool
+	}{
+		{name: "protobuf", encoding: model.EventEncodingProtobuf, body: protobufBody},
+		{name: "json", encoding: model.EventEncodingJson, body: jsonBody},
+		{name: "json of the mqtt integration", encoding: model.EventEncodingJson, body: []byte(`{
+			"deduplicationId": "d5a3e2b4-6a5f-4bd0-9d8b-7b4b3d4e5f60",
+			"deviceInfo": {"tenantId": "tenant-1", "applicationId": "app-1", "deviceProfileId": "profile-1", "devEui": "0102030405060708"},
+			"fPort": 2,
+			"object": {"temperature": 21.5, "valve": "open"}
+		}`)},
+		{name: "json as protobuf", encoding: model.EventEncodingProtobuf, body: jsonBody, err: true},
+		{name: "protobuf as json", encoding: model.EventEncodingJson, body: protobufBody, err: true},
+		{name: "unknown encoding", encoding: "xml", body: jsonBody, err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := &integration.UplinkEvent{}
+			err := UnmarshalEvent(test.encoding, test.body, actual)
+			if test.err {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(actual, expected) {
+				t.Fatalf("got %v, expected %v", actual, expected)
+			}
+		})
+	}
+}
+
+func TestMqttEventSubscription(t *testing.T) {
+	log.Init(configuration.Config{})
+	broker, brokerUrl := newTestBroker(t)
+	tenants := &fakeTenantClient{requests: make(chan string, 10)}
+	c := newTestMqttController(t, configuration.Config{
+		IngestionMode:  model.IngestionModeMqtt,
+		MqttBroker:     brokerUrl,
+		MqttClientId:   "test",
+		MqttEventTopic: "application/+/device/+/event/+",
+		MqttEncoding:   model.EventEncodingJson,
+	})
+	c.chirpTenant = tenants
+	// the connect handler subscribes asynchronously
+	deadline := time.Now().Add(5 * time.Second)
+	for len(broker.Topics.Subscribers("application/app-1/device/0102030405060708/event/up").Subscriptions) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for subscription")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	tests := []struct {
+		name       string
+		topic      string
+		event      proto.Message
+		dispatched bool
+	}{
+		{
+			name:       "uplink",
+			topic:      "application/app-1/device/0102030405060708/event/up",
+			event:      &integration.UplinkEvent{DeviceInfo: &integration.DeviceInfo{TenantId: "tenant-1", ApplicationId: "app-1", DevEui: "0102030405060708"}},
+			dispatched: true,
+		},
+		{
+			name:       "join with upper case eui in topic",
+			topic:      "application/app-1/device/A1B2C3D4E5F60708/event/join",
+			event:      &integration.JoinEvent{DeviceInfo: &integration.DeviceInfo{TenantId: "tenant-2", ApplicationId: "app-1", DevEui: "a1b2c3d4e5f60708"}},
+			dispatched: true,
+		},
+		{
+			name:  "other application in payload",
+			topic: "application/app-1/device/0102030405060708/event/up",
+			event: &integration.UplinkEvent{DeviceInfo: &integration.DeviceInfo{TenantId: "tenant-3", ApplicationId: "app-2", DevEui: "0102030405060708"}},
+		},
+		{
+			name:  "other device in payload",
+			topic: "application/app-1/device/0102030405060708/event/status",
+			event: &integration.StatusEvent{DeviceInfo: &integration.DeviceInfo{TenantId: "tenant-4", ApplicationId: "app-1", DevEui: "0807060504030201"}},
+		},
+		{
+			name:  "unsupported event type",
+			topic: "application/app-1/device/0102030405060708/event/ack",
+			event: &integration.AckEvent{DeviceInfo: &integration.DeviceInfo{TenantId: "tenant-5", ApplicationId: "app-1", DevEui: "0102030405060708"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payload, err := protojson.Marshal(test.event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = broker.Publish(test.topic, payload, false, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !test.dispatched {
+				select {
+				case tenantId := <-tenants.requests:
+					t.Fatalf("unexpected dispatch of event of tenant %s", tenantId)
+				case <-time.After(500 * time.Millisecond):
+				}
+				return
+			}
+			select {
+			case tenantId := <-tenants.requests:
+				expected := test.event.(chirpstackEvent).GetDeviceInfo().TenantId
+				if tenantId != expected {
+					t.Fatalf("expected dispatch of event of tenant %s, got %s", expected, tenantId)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("timeout waiting for dispatch")
+			}
+		})
+	}
+}
+
+func TestMqttDownlink(t *testing.T) {
+	log.Init(configuration.Config{})
+	for _, encoding := range []string{model.EventEncodingJson, model.EventEncodingProtobuf} {
+		t.Run(encoding, func(t *testing.T) {
+			broker, brokerUrl := newTestBroker(t)
+			received := make(chan packets.Packet, 1)
+			err := broker.Subscribe("application/+/device/+/command/down", 1, func(_ *mqtt.Client, _ packets.Subscription, pk packets.Packet) {
+				received <- pk
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := newTestMqttController(t, configuration.Config{
+				MqttBroker:    brokerUrl,
+				MqttClientId:  "test",
+				MqttEncoding:  encoding,
+				MqttDownlinks: true,
+			})
+			c.chirpDevice = &fakeDeviceClient{applicationId: "app-1"}
+
+			item := &api.DeviceQueueItem{DevEui: "0102030405060708", FPort: 10, Confirmed: true, Data: []byte{1, 2, 3}}
+			ctx, cf := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cf()
+			id, err := c.enqueueDownlink(ctx, item)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id == "" {
+				t.Fatal("expected queue item id")
+			}
+			select {
+			case pk := <-received:
+				if pk.TopicName != "application/app-1/device/0102030405060708/command/down" {
+					t.Fatalf("unexpected topic %s", pk.TopicName)
+				}
+				actual := &api.DeviceQueueItem{}
+				err = UnmarshalEvent(encoding, pk.Payload, actual)
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected := &api.DeviceQueueItem{Id: id, DevEui: "0102030405060708", FPort: 10, Confirmed: true, Data: []byte{1, 2, 3}}
+				if !proto.Equal(actual, expected) {
+					t.Fatalf("got %v, expected %v", actual, expected)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("timeout waiting for downlink")
+			}
+		})
+	}
+}
+
+// newTestBroker starts an embedded broker, which allows all clients.
+func newTestBroker(t *testing.T) (broker *mqtt.Server, brokerUrl string) {
+	broker = mqtt.New(&mqtt.Options{
+		InlineClient: true,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	err := broker.AddHook(new(auth.AllowHook), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tcp := listeners.NewTCP(listeners.Config{ID: "tcp", Address: "127.0.0.1:0"})
+	err = broker.AddListener(tcp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = broker.Serve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = broker.Close()
+	})
+	return broker, "tcp://" + tcp.Address()
+}
+
+func newTestMqttController(t *testing.T, config configuration.Config) *Controller {
+	localCache, err := cache.New(cache.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Controller{config: config, cache: localCache}
+	ctx, cf := context.WithCancel(context.Background())
+	t.Cleanup(cf)
+	err = c.setupMqtt(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// fakeTenantClient reports requested tenants, which are not managed by the connector.
+type fakeTenantClient struct {
+	api.TenantServiceClient
+	requests chan string
+}
+
+func (f *fakeTenantClient) Get(_ context.Context, in *api.GetTenantRequest, _ ...grpc.CallOption) (*api.GetTenantResponse, error) {
+	f.requests <- in.Id
+	return &api.GetTenantResponse{Tenant: &api.Tenant{Id: in.Id}}, nil
+}
+
+type fakeDeviceClient struct {
+	api.DeviceServiceClient
+	applicationId string
+}
+
+func (f *fakeDeviceClient) Get(_ context.Context, in *api.GetDeviceRequest, _ ...grpc.CallOption) (*api.GetDeviceResponse, error) {
+	return &api.GetDeviceResponse{Device: &api.Device{DevEui: in.DevEui, ApplicationId: f.applicationId}}, nil
+}
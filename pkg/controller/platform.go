@@ -0,0 +1,131 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	device_repo_model "github.com/SENERGY-Platform/device-repository/lib/model"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	platform_connector_lib_model "github.com/SENERGY-Platform/platform-connector-lib/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/cache"
+)
+
+// errNoConnector is returned by operations, which require the platform connector, if KAFKA_BOOTSTRAP is not configured.
+var errNoConnector = errors.New("platform connector not configured, KAFKA_BOOTSTRAP is empty")
+
+// The following helpers access the platform with the platform connector, if configured.
+// Without kafka, reads and updates fall back to the device repository with the admin token,
+// while operations needing a user token, like creating devices, return errNoConnector.
+
+func (c *Controller) adminToken() string {
+	c.jwtMux.RLock()
+	defer c.jwtMux.RUnlock()
+	return "Bearer " + c.jwt.AccessToken
+}
+
+// userToken returns a token of the user including the "Bearer " prefix.
+func (c *Controller) userToken(userId string) (string, error) {
+	if c.connector == nil {
+		return "", errNoConnector
+	}
+	token, err := c.connector.Security().GetCachedUserToken(userId, platform_connector_lib_model.RemoteInfo{})
+	return string(token), err
+}
+
+func (c *Controller) getPlatformDevice(userId string, localDeviceId string) (models.Device, error) {
+	if c.connector == nil {
+		return cache.Use(c.cache, "lpc_device_"+userId+"_"+localDeviceId, func() (models.Device, error) {
+			device, err, _ := c.deviceRepo.ReadDeviceByLocalId(userId, localDeviceId, c.adminToken(), models.Read)
+			return device, err
+		}, nil, time.Minute)
+	}
+	token, err := c.connector.Security().GetCachedUserToken(userId, platform_connector_lib_model.RemoteInfo{})
+	if err != nil {
+		return models.Device{}, err
+	}
+	return c.connector.IotCache.GetDeviceByLocalId(token, localDeviceId)
+}
+
+func (c *Controller) getPlatformDeviceType(userId string, deviceTypeId string) (models.DeviceType, error) {
+	if c.connector == nil {
+		return cache.Use(c.cache, "lpc_device_type_"+deviceTypeId, func() (models.DeviceType, error) {
+			dt, err, _ := c.deviceRepo.ReadDeviceType(deviceTypeId, c.adminToken())
+			return dt, err
+		}, nil, time.Minute)
+	}
+	token, err := c.connector.Security().GetCachedUserToken(userId, platform_connector_lib_model.RemoteInfo{})
+	if err != nil {
+		return models.DeviceType{}, err
+	}
+	return c.connector.IotCache.GetDeviceType(token, deviceTypeId)
+}
+
+// updatePlatformDevice updates the device as its owner.
+// Without the platform connector, the device is updated with the admin token after checking, that it still belongs to the owner.
+func (c *Controller) updatePlatformDevice(ownerId string, device models.Device) error {
+	if c.connector == nil {
+		existing, err, _ := c.deviceRepo.ReadDevice(device.Id, c.adminToken(), models.Read)
+		if err != nil {
+			return err
+		}
+		if existing.OwnerId != ownerId || device.OwnerId != ownerId {
+			return errors.Join(model.ErrForbidden, fmt.Errorf("device %s is not owned by %s", device.Id, ownerId))
+		}
+		_, err, _ = c.deviceRepo.SetDevice(c.adminToken(), device, device_repo_model.DeviceUpdateOptions{})
+		if err != nil {
+			return err
+		}
+		return c.cache.Remove("lpc_device_" + ownerId + "_" + device.LocalId)
+	}
+	token, err := c.connector.Security().GetCachedUserToken(ownerId, platform_connector_lib_model.RemoteInfo{})
+	if err != nil {
+		return err
+	}
+	_, err = c.connector.IotCache.UpdateDevice(token, device)
+	return err
+}
+
+// createPlatformDevice creates the device as its owner. Not supported without the platform connector.
+func (c *Controller) createPlatformDevice(ownerId string, device models.Device) (models.Device, error) {
+	if c.connector == nil {
+		return device, errNoConnector
+	}
+	token, err := c.connector.Security().GetCachedUserToken(ownerId, platform_connector_lib_model.RemoteInfo{})
+	if err != nil {
+		return device, err
+	}
+	return c.connector.IotCache.CreateDevice(token, device)
+}
+
+func (c *Controller) updatePlatformDeviceType(dt models.DeviceType) (models.DeviceType, error) {
+	if c.connector == nil {
+		result, err, _ := c.deviceRepo.SetDeviceType(c.adminToken(), dt, device_repo_model.DeviceTypeUpdateOptions{})
+		if err != nil {
+			return result, err
+		}
+		return result, c.cache.Remove("lpc_device_type_" + dt.Id)
+	}
+	token, err := c.connector.Security().Access()
+	if err != nil {
+		return dt, err
+	}
+	return c.connector.IotCache.UpdateDeviceType(token, dt)
+}
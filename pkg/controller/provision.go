@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/Nerzal/gocloak/v13"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/configuration"
 	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
 	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
 	"google.golang.org/grpc/codes"
@@ -66,28 +67,48 @@ func (c *Controller) ProvisionUser(ctx context.Context, chirpUserId string, user
 	if err != nil {
 		return err
 	}
+	err = c.rdb.Set(ctx, fmt.Sprintf(model.RedisKeyFmtUserEmail, *userInfo.Sub), *userInfo.Email, 0).Err()
+	if err != nil {
+		return err
+	}
+
+	// apply tenant plan
+	plan, err := c.getTenantPlan(ctx, *userInfo.Sub)
+	if err != nil {
+		return err
+	}
+	err = c.applyTenantPlan(ctx, tenantId, *userInfo.Email, *userInfo.Sub, plan)
+	if err != nil {
+		return err
+	}
 
 	// add user to tenant if needed
-	_, err = c.chirpTenant.GetUser(ctx, &api.GetTenantUserRequest{
+	tenantUser := &api.TenantUser{
+		TenantId:       tenantId,
+		UserId:         chirpUserId,
+		Email:          *userInfo.Email,
+		IsGatewayAdmin: plan.IsGatewayAdmin,
+		IsAdmin:        plan.IsAdmin,
+		IsDeviceAdmin:  plan.IsDeviceAdmin,
+	}
+	existingTenantUser, err := c.chirpTenant.GetUser(ctx, &api.GetTenantUserRequest{
 		TenantId: tenantId,
 		UserId:   chirpUserId,
 	})
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
-			_, err = c.chirpTenant.AddUser(ctx, &api.AddTenantUserRequest{TenantUser: &api.TenantUser{
-				TenantId:       tenantId,
-				UserId:         chirpUserId,
-				Email:          *userInfo.Email,
-				IsGatewayAdmin: false,
-				IsAdmin:        false,
-				IsDeviceAdmin:  false,
-			}})
+			_, err = c.chirpTenant.AddUser(ctx, &api.AddTenantUserRequest{TenantUser: tenantUser})
 			if err != nil {
 				return err
 			}
 		} else {
 			return err
 		}
+	} else if len(c.config.TenantPlans) > 0 && (existingTenantUser.TenantUser.IsAdmin != plan.IsAdmin || existingTenantUser.TenantUser.IsDeviceAdmin != plan.IsDeviceAdmin || existingTenantUser.TenantUser.IsGatewayAdmin != plan.IsGatewayAdmin) {
+		_, err = c.chirpTenant.UpdateUser(ctx, &api.UpdateTenantUserRequest{TenantUser: tenantUser})
+		if err != nil {
+			return err
+		}
 	}
 
 	// get app id
@@ -101,6 +122,18 @@ func (c *Controller) ProvisionUser(ctx context.Context, chirpUserId string, user
 	integration, err := c.chirpApp.GetHttpIntegration(ctx, &api.GetHttpIntegrationRequest{
 		ApplicationId: appId,
 	})
+	if c.config.IngestionMode != model.IngestionModeHttp {
+		// events are read from redis or mqtt, the http integration would duplicate them
+		if err == nil {
+			_, err = c.chirpApp.DeleteHttpIntegration(ctx, &api.DeleteHttpIntegrationRequest{
+				ApplicationId: appId,
+			})
+			return err
+		} else if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		return err
+	}
 	if err == nil {
 		if integration.Integration.EventEndpointUrl != endpoint || integration.Integration.Encoding != encoding {
 			_, err = c.chirpApp.DeleteHttpIntegration(ctx, &api.DeleteHttpIntegrationRequest{
@@ -124,15 +157,16 @@ func (c *Controller) ProvisionUser(ctx context.Context, chirpUserId string, user
 }
 
 func (c *Controller) ProvisionAllUsers() (err error) {
-	getUsersCtx, getUsersCf := context.WithTimeout(context.Background(), 10*time.Second)
+	getUsersCtx, getUsersCf := context.WithTimeout(context.Background(), time.Minute)
 	defer getUsersCf()
-	c.jwtMux.RLock()
-	kcUsers, err := c.gocloakClient.GetUsers(getUsersCtx, c.jwt.AccessToken, "master", gocloak.GetUsersParams{})
-	c.jwtMux.RUnlock()
+	kcUsers, complete, err := c.listKeycloakUsers(getUsersCtx)
 	mux := sync.Mutex{}
 	if err != nil {
 		return err
 	}
+	if !complete {
+		log.Logger.Warn("keycloak user listing is incomplete, provisioning listed users only", "listed", len(kcUsers))
+	}
 	wg := sync.WaitGroup{}
 	for _, kcUser := range kcUsers {
 		wg.Go(func() {
@@ -174,10 +208,8 @@ func (c *Controller) DeleteUser(ctx context.Context, email string) error {
 }
 
 func (c *Controller) DeleteOutdatedUsers() error {
-	ctx, cf := context.WithTimeout(context.Background(), 10*time.Second)
-	c.jwtMux.RLock()
-	kcUsers, err := c.gocloakClient.GetUsers(ctx, c.jwt.AccessToken, "master", gocloak.GetUsersParams{})
-	c.jwtMux.RUnlock()
+	ctx, cf := context.WithTimeout(context.Background(), time.Minute)
+	kcUsers, err := c.listKeycloakUsersForDeletion(ctx)
 	cf()
 	if err != nil {
 		return err
@@ -236,12 +268,14 @@ func (c *Controller) DeleteOutdatedUsers() error {
 	return err
 }
 
-func prepareTenant(email string, userid string) *api.Tenant {
+func prepareTenant(email string, userid string, plan configuration.TenantPlan) *api.Tenant {
 	return &api.Tenant{
 		Name:                email,
-		PrivateGatewaysUp:   true,
-		PrivateGatewaysDown: true,
-		CanHaveGateways:     true,
+		PrivateGatewaysUp:   plan.PrivateGatewaysUp,
+		PrivateGatewaysDown: plan.PrivateGatewaysDown,
+		CanHaveGateways:     plan.CanHaveGateways,
+		MaxGatewayCount:     plan.MaxGatewayCount,
+		MaxDeviceCount:      plan.MaxDeviceCount,
 		Tags: map[string]string{
 			"Managed-By":         "lorawan-platform-connector",
 			model.ChirpTagUserId: userid,
@@ -250,11 +284,88 @@ func prepareTenant(email string, userid string) *api.Tenant {
 }
 
 func (c *Controller) createTenant(ctx context.Context, email string, userid string) (tenant *api.CreateTenantResponse, err error) {
+	plan, err := c.getTenantPlan(ctx, userid)
+	if err != nil {
+		return nil, err
+	}
 	return c.chirpTenant.Create(ctx, &api.CreateTenantRequest{
-		Tenant: prepareTenant(email, userid),
+		Tenant: prepareTenant(email, userid, plan),
 	})
 }
 
+// getTenantPlan selects the configured tenant plan matching the keycloak realm roles, groups and attributes of the user.
+func (c *Controller) getTenantPlan(ctx context.Context, userid string) (configuration.TenantPlan, error) {
+	if len(c.config.TenantPlans) == 0 || userid == "" {
+		return configuration.DefaultTenantPlan, nil
+	}
+	c.jwtMux.RLock()
+	accessToken := c.jwt.AccessToken
+	c.jwtMux.RUnlock()
+	user, err := c.gocloakClient.GetUserByID(ctx, accessToken, c.config.KeycloakRealm, userid)
+	if err != nil {
+		return configuration.TenantPlan{}, errors.Join(fmt.Errorf("unable to read user from keycloak"), err)
+	}
+	kcRoles, err := c.gocloakClient.GetCompositeRealmRolesByUserID(ctx, accessToken, c.config.KeycloakRealm, userid)
+	if err != nil {
+		return configuration.TenantPlan{}, errors.Join(fmt.Errorf("unable to read realm roles from keycloak"), err)
+	}
+	kcGroups, err := c.gocloakClient.GetUserGroups(ctx, accessToken, c.config.KeycloakRealm, userid, gocloak.GetGroupsParams{})
+	if err != nil {
+		return configuration.TenantPlan{}, errors.Join(fmt.Errorf("unable to read groups from keycloak"), err)
+	}
+	roles := []string{}
+	for _, role := range kcRoles {
+		if role != nil && role.Name != nil {
+			roles = append(roles, *role.Name)
+		}
+	}
+	groups := []string{}
+	for _, group := range kcGroups {
+		if group == nil {
+			continue
+		}
+		if group.Name != nil {
+			groups = append(groups, *group.Name)
+		}
+		if group.Path != nil {
+			groups = append(groups, *group.Path)
+		}
+	}
+	attributes := map[string][]string{}
+	if user.Attributes != nil {
+		attributes = *user.Attributes
+	}
+	return c.config.TenantPlans.Select(roles, groups, attributes), nil
+}
+
+// applyTenantPlan updates the tenant settings and quotas if they differ from the plan.
+// Without configured plans, existing tenants are only updated if UpdateTenants is set.
+func (c *Controller) applyTenantPlan(ctx context.Context, tenantId string, email string, userid string, plan configuration.TenantPlan) error {
+	if len(c.config.TenantPlans) == 0 && !c.config.UpdateTenants {
+		return nil
+	}
+	tenant, err := c.chirpTenant.Get(ctx, &api.GetTenantRequest{Id: tenantId})
+	if err != nil {
+		return err
+	}
+	if tenant.Tenant.CanHaveGateways == plan.CanHaveGateways &&
+		tenant.Tenant.PrivateGatewaysUp == plan.PrivateGatewaysUp &&
+		tenant.Tenant.PrivateGatewaysDown == plan.PrivateGatewaysDown &&
+		tenant.Tenant.MaxGatewayCount == plan.MaxGatewayCount &&
+		tenant.Tenant.MaxDeviceCount == plan.MaxDeviceCount {
+		return nil
+	}
+	t := prepareTenant(email, userid, plan)
+	t.Id = tenantId
+	t.Description = tenant.Tenant.Description
+	_, err = c.chirpTenant.Update(ctx, &api.UpdateTenantRequest{Tenant: t})
+	if err != nil {
+		return err
+	}
+	log.Logger.Info("applied tenant plan", "user_id", userid, "tenant_id", tenantId, "plan", plan.Name)
+	return nil
+}
+
 func (c *Controller) createApp(ctx context.Context, tenantId string) (app *api.CreateApplicationResponse, err error) {
 	return c.chirpApp.Create(ctx, &api.CreateApplicationRequest{
 		Application: &api.Application{
@@ -327,7 +438,11 @@ func (c *Controller) getOrCreateChirpstackTenantId(ctx context.Context, email st
 		log.Logger.Error("found multiple tenants", "email", email)
 		return "", fmt.Errorf("found multiple tenants")
 	} else if c.config.UpdateTenants {
-		t := prepareTenant(email, userid)
+		plan, err := c.getTenantPlan(ctx, userid)
+		if err != nil {
+			return "", err
+		}
+		t := prepareTenant(email, userid, plan)
 		t.Id = tenants[0].Id
 		_, err = c.chirpTenant.Update(ctx, &api.UpdateTenantRequest{Tenant: t})
 		if err != nil {
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+var errSecretStoreDisabled = errors.Join(model.ErrNotFound, fmt.Errorf("secret store is not configured"))
+
+// RotateSecretMasterKey re-encrypts all secrets with the first configured master key. Requires an admin token.
+func (c *Controller) RotateSecretMasterKey(ctx context.Context, token jwt.Token) (*model.SecretRotation, error) {
+	if !token.IsAdmin() {
+		return nil, errors.Join(model.ErrForbidden, fmt.Errorf("only admins may rotate the master key"))
+	}
+	if c.secrets == nil {
+		return nil, errSecretStoreDisabled
+	}
+	rotated, err := c.secrets.RotateMasterKey(ctx, token.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	return &model.SecretRotation{Rotated: rotated}, nil
+}
+
+// ListSecretAudit returns the audit trail of the secret store, newest first. Requires an admin token.
+func (c *Controller) ListSecretAudit(ctx context.Context, token jwt.Token, limit int64, offset int64) ([]model.SecretAuditEntry, error) {
+	if !token.IsAdmin() {
+		return nil, errors.Join(model.ErrForbidden, fmt.Errorf("only admins may read the audit trail"))
+	}
+	if c.secrets == nil {
+		return nil, errSecretStoreDisabled
+	}
+	return c.secrets.ListAudit(ctx, limit, offset)
+}
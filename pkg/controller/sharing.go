@@ -0,0 +1,333 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	device_repo "github.com/SENERGY-Platform/device-repository/lib/model"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"github.com/chirpstack/chirpstack/api/go/v4/api"
+	"github.com/go-redis/redis/v8"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const shareLevelRead = "read"
+const shareLevelWrite = "write"
+const shareSyncBatchSize = 100
+
+type tenantMember struct {
+	ownerId string
+	userId  string
+}
+
+// SyncDeviceRights reflects the platform permissions of a device in the chirpstack tenant of its owner.
+func (c *Controller) SyncDeviceRights(ctx context.Context, deviceId string) error {
+	c.jwtMux.RLock()
+	adminToken := "Bearer " + c.jwt.AccessToken
+	c.jwtMux.RUnlock()
+	device, err, code := c.deviceRepo.ReadExtendedDevice(deviceId, adminToken, models.Read, true)
+	if code == http.StatusNotFound {
+		return c.syncResourceShares(ctx, model.PermissionsTopicDevices, deviceId, "", nil)
+	}
+	if err != nil {
+		return err
+	}
+	if device.DeviceType == nil || !deviceTypeManagedByLorawanPlatformConnector(*device.DeviceType) {
+		return nil
+	}
+	return c.syncRights(ctx, adminToken, model.PermissionsTopicDevices, deviceId, device.OwnerId)
+}
+
+// SyncHubRights reflects the platform permissions of a hub in the chirpstack tenant of its owner.
+func (c *Controller) SyncHubRights(ctx context.Context, hubId string) error {
+	c.jwtMux.RLock()
+	adminToken := "Bearer " + c.jwt.AccessToken
+	c.jwtMux.RUnlock()
+	hub, err, code := c.deviceRepo.ReadHub(hubId, adminToken, models.Read)
+	if code == http.StatusNotFound {
+		return c.syncResourceShares(ctx, model.PermissionsTopicHubs, hubId, "", nil)
+	}
+	if err != nil {
+		return err
+	}
+	if GetHubEUI(&hub) == nil {
+		return nil
+	}
+	return c.syncRights(ctx, adminToken, model.PermissionsTopicHubs, hubId, hub.OwnerId)
+}
+
+func (c *Controller) syncRights(ctx context.Context, adminToken string, topic string, id string, ownerId string) error {
+	resource, err, _ := c.perm.GetResource(adminToken, topic, id)
+	if err != nil {
+		return err
+	}
+	return c.syncResourceShares(ctx, topic, id, ownerId, resource.UserPermissions)
+}
+
+// syncResourceShares updates the share ledger of a resource and reconciles the tenant membership of all affected users.
+// The ledger is kept per owner, because chirpstack only supports membership of whole tenants. An empty ownerId removes all shares of the resource.
+func (c *Controller) syncResourceShares(ctx context.Context, topic string, id string, ownerId string, permissions map[string]permv2.PermissionsMap) error {
+	affected := map[tenantMember]bool{}
+	resourcePrefix := topic + "/" + id + "/"
+	ownerKey := fmt.Sprintf(model.RedisKeyFmtShareOwner, topic, id)
+
+	previousOwnerId, err := c.rdb.Get(ctx, ownerKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	if previousOwnerId != "" {
+		shares, err := c.rdb.HGetAll(ctx, fmt.Sprintf(model.RedisKeyFmtShares, previousOwnerId)).Result()
+		if err != nil {
+			return err
+		}
+		fields := []string{}
+		for field := range shares {
+			if userId, ok := strings.CutPrefix(field, resourcePrefix); ok {
+				fields = append(fields, field)
+				affected[tenantMember{ownerId: previousOwnerId, userId: userId}] = true
+			}
+		}
+		if len(fields) > 0 {
+			err = c.rdb.HDel(ctx, fmt.Sprintf(model.RedisKeyFmtShares, previousOwnerId), fields...).Err()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if ownerId == "" {
+		err = c.rdb.Del(ctx, ownerKey).Err()
+	} else {
+		for userId, permission := range permissions {
+			if userId == ownerId {
+				continue
+			}
+			level := ""
+			switch {
+			case permission.Write || permission.Administrate:
+				level = shareLevelWrite
+			case permission.Read:
+				level = shareLevelRead
+			default:
+				continue
+			}
+			err = c.rdb.HSet(ctx, fmt.Sprintf(model.RedisKeyFmtShares, ownerId), resourcePrefix+userId, level).Err()
+			if err != nil {
+				return err
+			}
+			affected[tenantMember{ownerId: ownerId, userId: userId}] = true
+		}
+		err = c.rdb.Set(ctx, ownerKey, ownerId, 0).Err()
+	}
+	if err != nil {
+		return err
+	}
+
+	for member := range affected {
+		err = errors.Join(err, c.reconcileTenantMember(ctx, member.ownerId, member.userId))
+	}
+	return err
+}
+
+// reconcileTenantMember adds the user to the tenant of the owner, or removes the user if nothing is shared anymore.
+// Chirpstack can not scope tenant membership to single devices or gateways, so members see all devices and gateways of the owner.
+// Write access to any device or hub makes the user device or gateway admin of the tenant, read access a plain member.
+func (c *Controller) reconcileTenantMember(ctx context.Context, ownerId string, userId string) error {
+	shares, err := c.rdb.HGetAll(ctx, fmt.Sprintf(model.RedisKeyFmtShares, ownerId)).Result()
+	if err != nil {
+		return err
+	}
+	member := false
+	isDeviceAdmin := false
+	isGatewayAdmin := false
+	for field, level := range shares {
+		topic, rest, _ := strings.Cut(field, "/")
+		if !strings.HasSuffix(rest, "/"+userId) {
+			continue
+		}
+		member = true
+		if level != shareLevelWrite {
+			continue
+		}
+		switch topic {
+		case model.PermissionsTopicDevices:
+			isDeviceAdmin = true
+		case model.PermissionsTopicHubs:
+			isGatewayAdmin = true
+		}
+	}
+
+	c.jwtMux.RLock()
+	owner, err := c.gocloakClient.GetUserByID(ctx, c.jwt.AccessToken, c.config.KeycloakRealm, ownerId)
+	c.jwtMux.RUnlock()
+	if err != nil {
+		return errors.Join(fmt.Errorf("unable to read owner from keycloak"), err)
+	}
+	c.jwtMux.RLock()
+	user, err := c.gocloakClient.GetUserByID(ctx, c.jwt.AccessToken, c.config.KeycloakRealm, userId)
+	c.jwtMux.RUnlock()
+	if err != nil {
+		return errors.Join(fmt.Errorf("unable to read user from keycloak"), err)
+	}
+	if owner.Email == nil || *owner.Email == "" || user.Email == nil || *user.Email == "" {
+		log.Logger.Warn("owner or user has no email, cannot reconcile tenant membership", "owner_id", ownerId, "user_id", userId)
+		return nil
+	}
+	tenantId, err := c.getOrCreateChirpstackTenantId(ctx, *owner.Email, ownerId)
+	if err != nil {
+		return err
+	}
+	chirpUserId, err := c.getOrCreateChirpstackUserId(ctx, *user.Email)
+	if err != nil {
+		return err
+	}
+
+	existing, err := c.chirpTenant.GetUser(ctx, &api.GetTenantUserRequest{TenantId: tenantId, UserId: chirpUserId})
+	if err != nil && status.Code(err) != codes.NotFound {
+		return err
+	}
+	exists := err == nil && existing.TenantUser != nil
+	err = nil
+	if exists && existing.TenantUser.IsAdmin {
+		return nil // never downgrade tenant admins
+	}
+	tenantUser := &api.TenantUser{
+		TenantId:       tenantId,
+		UserId:         chirpUserId,
+		Email:          *user.Email,
+		IsDeviceAdmin:  isDeviceAdmin,
+		IsGatewayAdmin: isGatewayAdmin,
+	}
+	action := ""
+	switch {
+	case !member && exists:
+		action = "removed"
+		_, err = c.chirpTenant.DeleteUser(ctx, &api.DeleteTenantUserRequest{TenantId: tenantId, UserId: chirpUserId})
+	case member && !exists:
+		action = "added"
+		_, err = c.chirpTenant.AddUser(ctx, &api.AddTenantUserRequest{TenantUser: tenantUser})
+	case member && (existing.TenantUser.IsDeviceAdmin != isDeviceAdmin || existing.TenantUser.IsGatewayAdmin != isGatewayAdmin):
+		action = "updated"
+		_, err = c.chirpTenant.UpdateUser(ctx, &api.UpdateTenantUserRequest{TenantUser: tenantUser})
+	}
+	if err != nil {
+		return err
+	}
+	if action != "" {
+		log.Logger.Info(action+" shared tenant membership", "owner_id", ownerId, "user_id", userId, "is_device_admin", isDeviceAdmin, "is_gateway_admin", isGatewayAdmin)
+	}
+	return nil
+}
+
+// SyncAllShares reconciles the shares of all managed devices and hubs, including shares created before the connector tracked them.
+func (c *Controller) SyncAllShares() error {
+	c.jwtMux.RLock()
+	adminToken := "Bearer " + c.jwt.AccessToken
+	c.jwtMux.RUnlock()
+	devices := map[string]string{}
+	var limit int64 = 1000
+	var offset int64 = 0
+	for {
+		deviceTypes, _, err, _ := c.deviceRepo.ListDeviceTypesV3(adminToken, device_repo.DeviceTypeListOptions{
+			AttributeKeys:   []string{model.DeviceTypeAttributeManagedByKey},
+			AttributeValues: []string{model.DeviceTypeAttributeManagedByValue},
+			Limit:           limit,
+			Offset:          offset,
+		})
+		if err != nil {
+			return err
+		}
+		for _, dt := range deviceTypes {
+			if !deviceTypeManagedByLorawanPlatformConnector(dt) {
+				continue
+			}
+			var deviceOffset int64 = 0
+			for {
+				page, _, err, _ := c.deviceRepo.ListExtendedDevices(adminToken, device_repo.ExtendedDeviceListOptions{
+					DeviceTypeIds: []string{dt.Id},
+					Limit:         limit,
+					Offset:        deviceOffset,
+				})
+				if err != nil {
+					return err
+				}
+				for _, device := range page {
+					devices[device.Id] = device.OwnerId
+				}
+				if int64(len(page)) < limit {
+					break
+				}
+				deviceOffset += limit
+			}
+		}
+		if int64(len(deviceTypes)) < limit {
+			break
+		}
+		offset += limit
+	}
+	hubs := map[string]string{}
+	offset = 0
+	for {
+		page, err, _ := c.deviceRepo.ListHubs(adminToken, device_repo.HubListOptions{Limit: limit, Offset: offset})
+		if err != nil {
+			return err
+		}
+		for _, hub := range page {
+			if GetHubEUI(&hub) != nil {
+				hubs[hub.Id] = hub.OwnerId
+			}
+		}
+		if int64(len(page)) < limit {
+			break
+		}
+		offset += limit
+	}
+	return errors.Join(
+		c.syncAllResourceShares(adminToken, model.PermissionsTopicDevices, devices),
+		c.syncAllResourceShares(adminToken, model.PermissionsTopicHubs, hubs),
+	)
+}
+
+// syncAllResourceShares reads the permissions of the resources (id -> owner id) in batches and reconciles their shares.
+func (c *Controller) syncAllResourceShares(adminToken string, topic string, owners map[string]string) (err error) {
+	ids := slices.Sorted(maps.Keys(owners))
+	for batch := range slices.Chunk(ids, shareSyncBatchSize) {
+		resources, err2, _ := c.perm.ListResourcesWithAdminPermission(adminToken, topic, permv2.ListOptions{Ids: batch})
+		if err2 != nil {
+			err = errors.Join(err, err2)
+			continue
+		}
+		for _, resource := range resources {
+			ctx, cf := context.WithTimeout(context.Background(), time.Minute)
+			err = errors.Join(err, c.syncResourceShares(ctx, topic, resource.Id, owners[resource.Id], resource.UserPermissions))
+			cf()
+		}
+	}
+	return err
+}
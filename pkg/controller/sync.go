@@ -34,9 +34,12 @@ func (c *Controller) Sync() (err error) {
 		c.SyncAllDeviceProfiles(),
 		c.SyncAllGateways(),
 		c.DeleteOutdatedGateways(),
+		c.SyncAllShares(),
 	)
 }
 
+// setupSync starts the event based and the hourly sync. Without kafka, platform changes are not consumed
+// and only reach chirpstack with the hourly sync, while chirpstack changes are still read from redis.
 func (c *Controller) setupSync(ctx context.Context) error {
 	err := c.setupEventSyncDevice(ctx)
 	if err != nil {
@@ -50,9 +53,13 @@ func (c *Controller) setupSync(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	err = c.setupEventSyncUser(ctx)
+	if err != nil {
+		return err
+	}
 	ticker := time.NewTicker(1 * time.Hour)
-	defer ticker.Stop()
 	go func() {
+		defer ticker.Stop()
 		for {
 			select {
 			case <-ctx.Done():
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"reflect"
 	"slices"
 	"strings"
@@ -31,7 +32,6 @@ import (
 
 	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
 	"github.com/SENERGY-Platform/platform-connector-lib/kafka"
-	platform_connector_lib_model "github.com/SENERGY-Platform/platform-connector-lib/model"
 	"github.com/SENERGY-Platform/service-commons/pkg/cache"
 
 	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
@@ -46,15 +46,17 @@ import (
 )
 
 func (c *Controller) SyncDevice(ctx context.Context, platformDevice *models.ExtendedDevice) error {
+	return c.syncDevice(ctx, platformDevice, false)
+}
+
+// syncDevice pushes the platform device to chirpstack. If the device exists in another application, it is transferred
+// if the previous owner released it or forceTransfer is set. Otherwise, the platform device is flagged as duplicate.
+func (c *Controller) syncDevice(ctx context.Context, platformDevice *models.ExtendedDevice, forceTransfer bool) error {
 	// ensure localId is lowercase, as chirpstack transforms it toLower and we wouldn't find the device anymore on events (platformDevice.LocalId is case-sensitive)
 	localIdLower := strings.ToLower(platformDevice.LocalId)
 	if platformDevice.Device.LocalId != localIdLower {
 		platformDevice.Device.LocalId = localIdLower
-		token, err := c.connector.Security().GetCachedUserToken(platformDevice.OwnerId, platform_connector_lib_model.RemoteInfo{})
-		if err != nil {
-			return err
-		}
-		_, err = c.connector.IotCache.UpdateDevice(token, platformDevice.Device)
+		err := c.updatePlatformDevice(platformDevice.OwnerId, platformDevice.Device)
 		if err != nil {
 			return err
 		}
@@ -74,8 +76,29 @@ func (c *Controller) SyncDevice(ctx context.Context, platformDevice *models.Exte
 	if getErr != nil && status.Code(getErr) != codes.NotFound {
 		return getErr
 	}
+	transferred := false
 	if chirpDevice != nil && chirpDevice.Device.ApplicationId != newChirpDevice.ApplicationId {
 		// device exists but in different application
+		reason, err := c.transferDeviceIfReleased(ctx, platformDevice, chirpDevice.Device, newChirpDevice, forceTransfer)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			chirpDevice, getErr = c.chirpDevice.Get(ctx, &api.GetDeviceRequest{
+				DevEui: platformDevice.LocalId,
+			})
+			if getErr != nil {
+				return getErr
+			}
+			transferred = true
+			model.UpsertDeviceAttribute(models.Attribute{
+				Key:    model.DeviceAttributeTransferredAtKey,
+				Value:  time.Now().Format(time.RFC3339),
+				Origin: model.AttributeOrigin,
+			}, &platformDevice.Device)
+		}
+	}
+	if chirpDevice != nil && chirpDevice.Device.ApplicationId != newChirpDevice.ApplicationId {
 		log.Logger.Warn("device exists in different application", "device_id", platformDevice.Id, "local_id", platformDevice.LocalId, "existing_chirp_app_id", chirpDevice.Device.ApplicationId, "new_chirp_app_id", newChirpDevice.ApplicationId)
 		updated := model.UpsertDeviceAttribute(models.Attribute{
 			Key:    model.DeviceAttributeDuplicateKey,
@@ -86,13 +109,14 @@ func (c *Controller) SyncDevice(ctx context.Context, platformDevice *models.Exte
 			// attribute already exists with same value, nothing to update
 			return nil
 		}
-		token, err := c.connector.Security().GetCachedUserToken(platformDevice.OwnerId, platform_connector_lib_model.RemoteInfo{})
+		return c.updatePlatformDevice(platformDevice.OwnerId, platformDevice.Device)
+	}
+	if model.RemoveDeviceAttribute(model.DeviceAttributeDuplicateKey, &platformDevice.Device) || transferred { // careful: lazy eval!
+		// device is no longer a duplicate
+		err := c.updatePlatformDevice(platformDevice.OwnerId, platformDevice.Device)
 		if err != nil {
 			return err
 		}
-		_, err = c.connector.IotCache.UpdateDevice(token, platformDevice.Device)
-
-		return err
 	}
 
 	deviceProfile, err := c.chirpDeviceProfile.Get(ctx, &api.GetDeviceProfileRequest{
@@ -129,15 +153,36 @@ func (c *Controller) SyncDevice(ctx context.Context, platformDevice *models.Exte
 		}
 	}
 
+	newKeys = c.mergeRedactedKeys(ctx, platformDevice.LocalId, newKeys, existingKeys)
+	newActivation = c.mergeRedactedActivation(ctx, platformDevice.LocalId, newActivation, existingActivation)
+
 	deviceNeedsKeyCreation = deviceNeedsKeyCreation && newKeys != nil
-	deviceNeedsUpdate := !deviceNeedsCreate && chirpDevice.Device.Name != name
+	var existingTags, existingVariables map[string]string
+	if !deviceNeedsCreate {
+		newChirpDevice.Description = chirpDevice.Device.Description
+		existingTags, existingVariables = chirpDevice.Device.Tags, chirpDevice.Device.Variables
+	}
+	managed, err := c.getManagedKeys(ctx, model.ManagedKeysKindDevice, platformDevice.LocalId)
+	if err != nil {
+		return err
+	}
+	var newManaged managedKeys
+	newChirpDevice.Description, newChirpDevice.Tags, newChirpDevice.Variables, newManaged = c.chirpstackDeviceFields(platformDevice.Attributes, newChirpDevice.Description, existingTags, existingVariables, managed)
+	deviceNeedsUpdate := !deviceNeedsCreate && (chirpDevice.Device.Name != name ||
+		chirpDevice.Device.Description != newChirpDevice.Description ||
+		!maps.Equal(chirpDevice.Device.Tags, newChirpDevice.Tags) ||
+		!maps.Equal(chirpDevice.Device.Variables, newChirpDevice.Variables))
 	deviceNeedsKeyUpdate := newKeys != nil && !deviceNeedsKeyCreation && (deviceNeedsCreate || (deviceProfile.DeviceProfile.SupportsOtaa && (existingKeys == nil || !reflect.DeepEqual(existingKeys, newKeys))))
 	deviceNeedsActivation := newActivation != nil && (deviceNeedsCreate || !reflect.DeepEqual(existingActivation, newActivation))
 
 	log.Logger.Debug("syncing device", "device.id", platformDevice.Id, "device.local_id", platformDevice.LocalId, "device.name", name, "device_needs_create", deviceNeedsCreate, "device_needs_update", deviceNeedsUpdate, "device_needs_key_creation", deviceNeedsKeyCreation, "device_needs_key_update", deviceNeedsKeyUpdate, "device_needs_activation", deviceNeedsActivation)
 
 	if !deviceNeedsActivation && !deviceNeedsCreate && !deviceNeedsUpdate && !deviceNeedsKeyUpdate {
-		return nil // nothing to do
+		err = c.setManagedKeys(ctx, model.ManagedKeysKindDevice, platformDevice.LocalId, managed, newManaged)
+		if err != nil {
+			return err
+		}
+		return c.redactDeviceKeys(ctx, platformDevice) // nothing to do in chirpstack
 	}
 
 	cuCtx, cuCf := context.WithTimeout(ctx, 10*time.Second)
@@ -154,6 +199,10 @@ func (c *Controller) SyncDevice(ctx context.Context, platformDevice *models.Exte
 	if err != nil {
 		return err
 	}
+	err = c.setManagedKeys(ctx, model.ManagedKeysKindDevice, platformDevice.LocalId, managed, newManaged)
+	if err != nil {
+		return err
+	}
 	keyCtx, keyCf := context.WithTimeout(ctx, 10*time.Second)
 	if deviceNeedsKeyCreation {
 		_, err = c.chirpDevice.CreateKeys(keyCtx, &api.CreateDeviceKeysRequest{
@@ -178,7 +227,7 @@ func (c *Controller) SyncDevice(ctx context.Context, platformDevice *models.Exte
 			return err
 		}
 	}
-	return nil
+	return c.redactDeviceKeys(ctx, platformDevice)
 }
 
 func (c *Controller) SyncAllDevices() (err error) {
@@ -252,12 +301,10 @@ func (c *Controller) DeleteOutdatedDevices() error {
 	cont := true
 	ctx, cf := context.WithTimeout(context.Background(), 10*time.Minute)
 	defer cf()
-	c.jwtMux.RLock()
-	kcUsers, err := c.gocloakClient.GetUsers(ctx, c.jwt.AccessToken, "master", gocloak.GetUsersParams{})
+	kcUsers, err := c.listKeycloakUsersForDeletion(ctx)
 	if err != nil {
 		return err
 	}
-	c.jwtMux.RUnlock()
 	wg := sync.WaitGroup{}
 	mux := sync.Mutex{}
 	var sErr error
@@ -342,12 +389,21 @@ func (c *Controller) DeleteOutdatedDevices() error {
 							if len(platformDevices) == len(chirpDevices.Result) {
 								continue
 							}
-							for _, localId := range localIds {
+							for _, chirpDevice := range chirpDevices.Result {
+								localId := chirpDevice.DevEui
 								if slices.ContainsFunc(platformDevices, func(platformDevice models.Device) bool {
 									return platformDevice.LocalId == localId
 								}) {
 									continue
 								}
+								if c.config.ChirpstackAdoption {
+									// misses in platform devices --> adopt
+									err = c.adoptChirpDevice(ctx, ownerId, chirpDevice)
+									if err != nil {
+										log.Logger.Error("unable to adopt chirpstack device", attributes.ErrorKey, err, "dev_eui", localId, "owner_id", ownerId)
+									}
+									continue
+								}
 								// misses in platform devices --> delete
 								_, err := c.chirpDevice.Delete(ctx, &api.DeleteDeviceRequest{
 									DevEui: localId,
@@ -392,7 +448,9 @@ func (c *Controller) setupEventSyncDevice(ctx context.Context) error {
 		}
 		switch command.Command {
 		case model.RightsCommand:
-			return nil
+			ctx2, cf := context.WithTimeout(ctx, 30*time.Second)
+			defer cf()
+			return c.SyncDeviceRights(ctx2, command.Id)
 		case model.PutCommand:
 			c.jwtMux.RLock()
 			devices, _, err, _ := c.deviceRepo.ListExtendedDevices("Bearer "+c.jwt.AccessToken, device_repo.ExtendedDeviceListOptions{
@@ -416,6 +474,10 @@ func (c *Controller) setupEventSyncDevice(ctx context.Context) error {
 			ctx2, cf := context.WithTimeout(ctx, 10*time.Second)
 			defer cf()
 			log.Logger.Debug("deleting device", "device_id", command.Device.Id, "local_id", command.Device.LocalId)
+			err = c.syncResourceShares(ctx2, model.PermissionsTopicDevices, command.Id, "", nil)
+			if err != nil {
+				log.Logger.Error("unable to remove device shares", attributes.ErrorKey, err, "device_id", command.Id)
+			}
 			chirpDevice, err := c.chirpDevice.Get(ctx2, &api.GetDeviceRequest{
 				DevEui: command.Device.LocalId,
 			})
@@ -428,7 +490,7 @@ func (c *Controller) setupEventSyncDevice(ctx context.Context) error {
 
 			// get user info
 			c.jwtMux.RLock()
-			user, err := c.gocloakClient.GetUserByID(ctx2, c.jwt.AccessToken, "master", command.Device.OwnerId)
+			user, err := c.gocloakClient.GetUserByID(ctx2, c.jwt.AccessToken, c.config.KeycloakRealm, command.Device.OwnerId)
 			c.jwtMux.RUnlock()
 			if err != nil {
 				return err
@@ -455,6 +517,11 @@ func (c *Controller) setupEventSyncDevice(ctx context.Context) error {
 			if err != nil && status.Code(err) != codes.NotFound {
 				return err
 			}
+			c.deleteDeviceKeySecrets(ctx2, command.Device.LocalId)
+			err = c.deleteManagedKeys(ctx2, model.ManagedKeysKindDevice, command.Device.LocalId)
+			if err != nil {
+				log.Logger.Error("unable to remove managed keys of deleted device", attributes.ErrorKey, err, "dev_eui", command.Device.LocalId)
+			}
 			return nil
 		default:
 			log.Logger.Warn("unhandeled command on device kafka topic", "command", command.Command)
@@ -466,9 +533,9 @@ func (c *Controller) setupEventSyncDevice(ctx context.Context) error {
 }
 
 func (c *Controller) prepareChirpDevice(ctx context.Context, platformDevice *models.ExtendedDevice, name string) (*api.Device, *api.DeviceActivation, *api.DeviceKeys, error) {
-	user, err := cache.Use(c.connector.IotCache.GetCache(), "lpc_user_"+platformDevice.OwnerId, func() (gocloak.User, error) {
+	user, err := cache.Use(c.cache, "lpc_user_"+platformDevice.OwnerId, func() (gocloak.User, error) {
 		c.jwtMux.RLock()
-		user, err := c.gocloakClient.GetUserByID(ctx, c.jwt.AccessToken, "master", platformDevice.OwnerId)
+		user, err := c.gocloakClient.GetUserByID(ctx, c.jwt.AccessToken, c.config.KeycloakRealm, platformDevice.OwnerId)
 		c.jwtMux.RUnlock()
 		if err != nil {
 			return gocloak.User{}, err
@@ -511,7 +578,7 @@ func (c *Controller) prepareChirpDevice(ctx context.Context, platformDevice *mod
 		Name:            name,
 		ApplicationId:   appId,
 		DeviceProfileId: deviceProvileId,
-		Description:     "Managed by lorawan-platform-connector",
+		Description:     chirpDeviceDescription,
 	}
 
 	activation := &api.DeviceActivation{
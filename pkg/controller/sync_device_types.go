@@ -23,6 +23,7 @@ import (
 	"reflect"
 	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -128,7 +129,9 @@ func (c *Controller) SyncAllDeviceProfiles() (err error) {
 	return err
 }
 
-func (c *Controller) ensureSyncedService(dt models.DeviceType, localServiceId string, event any) (serviceId string, err error) {
+// applyServiceSample merges the content variable inferred from the event into the event service with the local id.
+// The services of dt are copied before modification. serviceId is empty, if the service has not been created yet.
+func (c *Controller) applyServiceSample(dt models.DeviceType, localServiceId string, event any) (updated models.DeviceType, serviceId string, changed bool) {
 	service := &models.Service{
 		Id:          "",
 		LocalId:     localServiceId,
@@ -153,39 +156,27 @@ func (c *Controller) ensureSyncedService(dt models.DeviceType, localServiceId st
 				service.Attributes = s.Attributes
 				service.ServiceGroupKey = s.ServiceGroupKey
 			}
-			dt.Services[i] = *service
 			found = i
 			break
 		}
 	}
 	cv := prepareContentVariable(&event, base)
 	if cv == nil {
-		return service.Id, nil
+		return dt, service.Id, false
 	}
+	annotateContentVariable(c.annotationRulesOf(dt), cv, base, "")
 
 	if base != nil && reflect.DeepEqual(*base, *cv) {
-		return service.Id, nil
+		return dt, service.Id, false
 	}
 	service.Outputs[0].ContentVariable = *cv
+	dt.Services = slices.Clone(dt.Services)
 	if found == -1 {
 		dt.Services = append(dt.Services, *service)
 	} else {
 		dt.Services[found] = *service
 	}
-	token, err := c.connector.Security().Access()
-	if err != nil {
-		return "", err
-	}
-	dt, err = c.connector.IotCache.UpdateDeviceType(token, dt)
-	if err != nil {
-		return "", err
-	}
-	for _, s := range dt.Services {
-		if s.LocalId == localServiceId {
-			return s.Id, nil
-		}
-	}
-	return "", fmt.Errorf("service %s not found after update", localServiceId)
+	return dt, service.Id, true
 }
 
 func (c *Controller) setupEventSyncDeviceProfile(ctx context.Context) error {
@@ -274,6 +265,36 @@ func (c *Controller) setupEventSyncDeviceProfile(ctx context.Context) error {
 						cf()
 						log.Logger.Debug("Device profile synced successfully", "device_profile", profileId)
 					}
+
+					if pl.Service == "api.DeviceService" && pl.Method == "Update" {
+						devEui, ok := pl.Metadata["dev_eui"]
+						if !ok {
+							log.Logger.Error("dev eui not found in metadata")
+							continue
+						}
+						log.Logger.Debug("API Event Stream: Device Updated", "dev_eui", devEui)
+						ctx2, cf := context.WithTimeout(ctx, 1*time.Minute)
+						err = c.syncChirpDeviceEdits(ctx2, devEui)
+						cf()
+						if err != nil {
+							log.Logger.Error("error propagating device edits", attributes.ErrorKey, err, "dev_eui", devEui)
+						}
+					}
+
+					if pl.Service == "api.GatewayService" && pl.Method == "Update" {
+						gatewayId, ok := pl.Metadata["gateway_id"]
+						if !ok {
+							log.Logger.Error("gateway id not found in metadata")
+							continue
+						}
+						log.Logger.Debug("API Event Stream: Gateway Updated", "gateway_eui", gatewayId)
+						ctx2, cf := context.WithTimeout(ctx, 1*time.Minute)
+						err = c.syncChirpGatewayEdits(ctx2, gatewayId)
+						cf()
+						if err != nil {
+							log.Logger.Error("error propagating gateway edits", attributes.ErrorKey, err, "gateway_eui", gatewayId)
+						}
+					}
 				}
 
 			}
@@ -394,14 +415,66 @@ func (c *Controller) prepareDeviceType(listItem *api.DeviceProfileListItem, prof
 			}},
 		}},
 	}
+	deviceModel := listItem.DeviceName
+	if deviceModel == "" {
+		deviceModel = profile.Name
+	}
+	if listItem.VendorName != "" {
+		dt.Attributes = append(dt.Attributes, models.Attribute{
+			Key:    model.DeviceTypeAttributeVendorKey,
+			Value:  listItem.VendorName,
+			Origin: model.AttributeOrigin,
+		})
+	}
+	dt.Attributes = append(dt.Attributes, models.Attribute{
+		Key:    model.DeviceTypeAttributeModelKey,
+		Value:  deviceModel,
+		Origin: model.AttributeOrigin,
+	})
+	if estimator := profile.Tags[model.DeviceProfileTagLocationEstimator]; estimator != "" {
+		if validLocationEstimator(estimator) {
+			dt.Attributes = append(dt.Attributes, models.Attribute{
+				Key:    model.DeviceTypeAttributeLocationEstimatorKey,
+				Value:  estimator,
+				Origin: model.AttributeOrigin,
+			})
+		} else {
+			log.Logger.Error("unknown location estimator, skipping location estimation", "estimator", estimator, "device_profile_id", profile.Id)
+		}
+	}
+	if distance := profile.Tags[model.DeviceProfileTagLocationUpdateDistance]; distance != "" {
+		if _, err := strconv.ParseFloat(distance, 64); err == nil {
+			dt.Attributes = append(dt.Attributes, models.Attribute{
+				Key:    model.DeviceTypeAttributeLocationUpdateDistanceKey,
+				Value:  distance,
+				Origin: model.AttributeOrigin,
+			})
+		} else {
+			log.Logger.Error("invalid location update distance, skipping location updates", attributes.ErrorKey, err, "device_profile_id", profile.Id)
+		}
+	}
 	if base != nil {
 		for _, svc := range base.Services {
-			if svc.LocalId != "status" {
+			if svc.LocalId != "status" && !strings.HasPrefix(svc.LocalId, model.CommandServiceLocalIdPrefix) {
 				dt.Services = append(dt.Services, svc)
 			}
 		}
 		dt.ServiceGroups = base.ServiceGroups
 	}
+	commands, err := c.getDownlinkCommands(profile)
+	if err != nil {
+		// keep the existing command services, removing them would break their process bindings
+		log.Logger.Error("unable to get downlink commands, keeping existing command services", attributes.ErrorKey, err, "device_profile_id", profile.Id)
+		if base != nil {
+			for _, svc := range base.Services {
+				if strings.HasPrefix(svc.LocalId, model.CommandServiceLocalIdPrefix) {
+					dt.Services = append(dt.Services, svc)
+				}
+			}
+		}
+		return dt
+	}
+	dt.Services = append(dt.Services, c.prepareCommandServices(commands, base)...)
 	return dt
 }
 
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"regexp"
 	"slices"
@@ -42,6 +43,12 @@ import (
 )
 
 func (c *Controller) SyncGateway(ctx context.Context, hub *models.Hub) error {
+	return c.syncGateway(ctx, hub, false)
+}
+
+// syncGateway pushes the hub to chirpstack. If the gateway exists in another tenant, it is transferred
+// if the previous owner released it or forceTransfer is set. Otherwise, the hub is flagged as duplicate.
+func (c *Controller) syncGateway(ctx context.Context, hub *models.Hub, forceTransfer bool) error {
 	eui := GetHubEUI(hub)
 
 	if eui == nil {
@@ -49,7 +56,7 @@ func (c *Controller) SyncGateway(ctx context.Context, hub *models.Hub) error {
 	}
 
 	expiration := GetHubCertExpiration(hub)
-	if expiration != nil && time.Until(*expiration) < 30*24*time.Hour {
+	if expiration != nil && time.Until(*expiration) < 30*24*time.Hour && c.connector != nil {
 		err := c.connector.SendNotification(platform_connector_lib.Notification{
 			UserId:  hub.OwnerId,
 			Title:   "LoRaWAN Certificate Expiry Warning",
@@ -71,7 +78,7 @@ func (c *Controller) SyncGateway(ctx context.Context, hub *models.Hub) error {
 
 	// get user info
 	c.jwtMux.RLock()
-	user, err := c.gocloakClient.GetUserByID(ctx, c.jwt.AccessToken, "master", hub.OwnerId)
+	user, err := c.gocloakClient.GetUserByID(ctx, c.jwt.AccessToken, c.config.KeycloakRealm, hub.OwnerId)
 	c.jwtMux.RUnlock()
 	if err != nil {
 		return errors.Join(fmt.Errorf("unable to read user from keycloak"), err)
@@ -87,6 +94,27 @@ func (c *Controller) SyncGateway(ctx context.Context, hub *models.Hub) error {
 	}
 
 	// check if gateway exists in chirpstack
+	transferred := false
+	if gateway != nil && tenant != gateway.Gateway.TenantId {
+		reason, err := c.transferGatewayIfReleased(ctx, hub, gateway.Gateway, tenant, forceTransfer)
+		if err != nil {
+			return errors.Join(fmt.Errorf("unable to transfer gateway"), err)
+		}
+		if reason != "" {
+			gateway, err = c.chirpGateway.Get(ctx, &api.GetGatewayRequest{
+				GatewayId: *eui,
+			})
+			if err != nil {
+				return err
+			}
+			transferred = true
+			model.UpsertGatewayAttribute(models.Attribute{
+				Key:    model.GatewayAttributeTransferredAt,
+				Value:  time.Now().Format(time.RFC3339),
+				Origin: model.AttributeOrigin,
+			}, hub)
+		}
+	}
 	if gateway != nil && tenant != gateway.Gateway.TenantId {
 		if model.UpsertGatewayAttribute(models.Attribute{
 			Key:    model.DeviceAttributeDuplicateKey,
@@ -106,10 +134,16 @@ func (c *Controller) SyncGateway(ctx context.Context, hub *models.Hub) error {
 		gw = gateway.Gateway
 	}
 
-	newGateway, updateGw := prepareGateway(gw, hub, tenant)
+	managed, err := c.getManagedKeys(ctx, model.ManagedKeysKindGateway, *eui)
+	if err != nil {
+		return err
+	}
+	newGateway, updateGw, managedTags := prepareGateway(gw, hub, tenant, c.config.TagAttributePrefix, managed.Tags)
+	newManaged := managedKeys{Tags: managedTags}
 
-	update := fillHubAttributes(gw, hub)
-	update = c.linkHubDevices(ctx, gw, hub) || update // careful: lazy eval!
+	update := model.RemoveGatewayAttribute(model.DeviceAttributeDuplicateKey, hub) || transferred // careful: lazy eval!
+	update = fillHubAttributes(gw, hub) || update                                                 // careful: lazy eval!
+	update = c.linkHubDevices(ctx, gw, hub) || update                                             // careful: lazy eval!
 	if update {
 		_, err, _ := c.deviceRepo.SetHub("Bearer "+c.jwt.AccessToken, *hub)
 		if err != nil {
@@ -127,7 +161,7 @@ func (c *Controller) SyncGateway(ctx context.Context, hub *models.Hub) error {
 			return err
 		}
 		log.Logger.Debug("created gateway in chirpstack", "gateway_eui", eui, "tenant_id", tenant)
-		return nil
+		return c.setManagedKeys(ctx, model.ManagedKeysKindGateway, *eui, managed, newManaged)
 	}
 
 	if updateGw {
@@ -140,7 +174,7 @@ func (c *Controller) SyncGateway(ctx context.Context, hub *models.Hub) error {
 		}
 		log.Logger.Debug("updated gateway in chirpstack", "gateway_eui", eui, "tenant_id", tenant)
 	}
-	return nil
+	return c.setManagedKeys(ctx, model.ManagedKeysKindGateway, *eui, managed, newManaged)
 
 }
 
@@ -241,7 +275,7 @@ func (c *Controller) DeleteOutdatedGateways() (err error) {
 							}
 
 							c.jwtMux.RLock()
-							user, err2 := c.gocloakClient.GetUserByID(ctx, c.jwt.AccessToken, "master", hub.OwnerId)
+							user, err2 := c.gocloakClient.GetUserByID(ctx, c.jwt.AccessToken, c.config.KeycloakRealm, hub.OwnerId)
 							c.jwtMux.RUnlock()
 							if err2 != nil {
 								mux.Lock()
@@ -260,6 +294,10 @@ func (c *Controller) DeleteOutdatedGateways() (err error) {
 				if exists {
 					return
 				}
+				if c.config.ChirpstackAdoption {
+					c.adoptOrKeepChirpGateway(ctx, gw, hubs)
+					return
+				}
 				// no matching gateway found in repo, delete from chirpstack
 				_, err2 := c.chirpGateway.Delete(ctx, &api.DeleteGatewayRequest{
 					GatewayId: gw.GatewayId,
@@ -307,7 +345,9 @@ func (c *Controller) setupEventSyncGateway(ctx context.Context) error {
 		}
 		switch command.Command {
 		case model.RightsCommand:
-			return nil
+			ctx2, cf := context.WithTimeout(ctx, 30*time.Second)
+			defer cf()
+			return c.SyncHubRights(ctx2, command.Id)
 		case model.PutCommand:
 			// get latest version of hub
 			c.jwtMux.RLock()
@@ -323,13 +363,17 @@ func (c *Controller) setupEventSyncGateway(ctx context.Context) error {
 			defer cf()
 			return c.SyncGateway(ctx2, &hub)
 		case model.DeleteCommand:
+			ctx2, cf := context.WithTimeout(ctx, 10*time.Second)
+			defer cf()
+			err = c.syncResourceShares(ctx2, model.PermissionsTopicHubs, command.Id, "", nil)
+			if err != nil {
+				log.Logger.Error("unable to remove hub shares", attributes.ErrorKey, err, "hub_id", command.Id)
+			}
 			eui := GetHubEUI(&command.Hub)
 
 			if eui == nil {
 				return nil
 			}
-			ctx2, cf := context.WithTimeout(ctx, 10*time.Second)
-			defer cf()
 			log.Logger.Debug("deleting gateway", "hub_id", command.Hub.Id, "gateway_eui", eui)
 			gateway, err := c.chirpGateway.Get(ctx2, &api.GetGatewayRequest{
 				GatewayId: *eui,
@@ -344,7 +388,7 @@ func (c *Controller) setupEventSyncGateway(ctx context.Context) error {
 
 			// get user info
 			c.jwtMux.RLock()
-			user, err := c.gocloakClient.GetUserByID(ctx2, c.jwt.AccessToken, "master", command.Hub.OwnerId)
+			user, err := c.gocloakClient.GetUserByID(ctx2, c.jwt.AccessToken, c.config.KeycloakRealm, command.Hub.OwnerId)
 			c.jwtMux.RUnlock()
 			if err != nil {
 				return err
@@ -368,6 +412,10 @@ func (c *Controller) setupEventSyncGateway(ctx context.Context) error {
 			if err != nil && status.Code(err) != codes.NotFound {
 				return err
 			}
+			err = c.deleteManagedKeys(ctx2, model.ManagedKeysKindGateway, *eui)
+			if err != nil {
+				log.Logger.Error("unable to remove managed keys of deleted gateway", attributes.ErrorKey, err, "gateway_eui", *eui)
+			}
 			return nil
 		default:
 			log.Logger.Warn("unhandeled command on hub kafka topic", "command", command.Command)
@@ -378,7 +426,7 @@ func (c *Controller) setupEventSyncGateway(ctx context.Context) error {
 	})
 }
 
-func prepareGateway(gw *api.Gateway, hub *models.Hub, tenantId string) (*api.Gateway, bool) {
+func prepareGateway(gw *api.Gateway, hub *models.Hub, tenantId string, tagPrefix string, managedTags []string) (*api.Gateway, bool, []string) {
 	rv := &api.Gateway{
 		TenantId: tenantId,
 	}
@@ -446,7 +494,13 @@ func prepareGateway(gw *api.Gateway, hub *models.Hub, tenantId string) (*api.Gat
 			continue
 		}
 	}
-	return rv, updated
+	description, tags, newManagedTags := chirpstackGatewayFields(hub.Attributes, tagPrefix, rv.Description, rv.Tags, managedTags)
+	if description != rv.Description || !maps.Equal(tags, rv.Tags) {
+		updated = true
+	}
+	rv.Description = description
+	rv.Tags = tags
+	return rv, updated, newManagedTags
 }
 
 func fillHubAttributes(gateway *api.Gateway, hub *models.Hub) bool {
@@ -0,0 +1,328 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	device_repo "github.com/SENERGY-Platform/device-repository/lib/model"
+	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/chirpstack/chirpstack/api/go/v4/api"
+	"github.com/go-redis/redis/v8"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const transferLogLength = 10000
+
+// TransferDevice moves the chirpstack device of a platform device flagged as duplicate into the tenant of its owner. Requires an admin token.
+func (c *Controller) TransferDevice(ctx context.Context, token jwt.Token, deviceId string) error {
+	if !token.IsAdmin() {
+		return errors.Join(model.ErrForbidden, fmt.Errorf("only admins may approve transfers"))
+	}
+	device, err, code := c.deviceRepo.ReadExtendedDevice(deviceId, token.Token, models.Read, true)
+	if err != nil {
+		return deviceRepoHandleErr(err, code)
+	}
+	if device.DeviceType == nil || !deviceTypeManagedByLorawanPlatformConnector(*device.DeviceType) {
+		return errors.Join(model.ErrBadRequest, fmt.Errorf("device is not managed by %s", model.DeviceTypeAttributeManagedByValue))
+	}
+	return c.syncDevice(ctx, &device, true)
+}
+
+// TransferGateway moves the chirpstack gateway of a hub flagged as duplicate into the tenant of its owner. Requires an admin token.
+func (c *Controller) TransferGateway(ctx context.Context, token jwt.Token, hubId string) error {
+	if !token.IsAdmin() {
+		return errors.Join(model.ErrForbidden, fmt.Errorf("only admins may approve transfers"))
+	}
+	hub, err, code := c.deviceRepo.ReadHub(hubId, token.Token, models.Read)
+	if err != nil {
+		return deviceRepoHandleErr(err, code)
+	}
+	if GetHubEUI(&hub) == nil {
+		return errors.Join(model.ErrBadRequest, fmt.Errorf("hub does not have a valid EUI"))
+	}
+	return c.syncGateway(ctx, &hub, true)
+}
+
+// ListTransfers returns the most recent transfers, newest first. Requires an admin token.
+func (c *Controller) ListTransfers(ctx context.Context, token jwt.Token, limit int64, offset int64) ([]model.Transfer, error) {
+	if !token.IsAdmin() {
+		return nil, errors.Join(model.ErrForbidden, fmt.Errorf("only admins may list transfers"))
+	}
+	entries, err := c.rdb.LRange(ctx, model.RedisKeyTransfers, offset, offset+limit-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	transfers := []model.Transfer{}
+	for _, entry := range entries {
+		var transfer model.Transfer
+		err = json.Unmarshal([]byte(entry), &transfer)
+		if err != nil {
+			log.Logger.Error("unable to unmarshal transfer", attributes.ErrorKey, err)
+			continue
+		}
+		transfers = append(transfers, transfer)
+	}
+	return transfers, nil
+}
+
+// transferDeviceIfReleased moves an existing chirpstack device into the application of the new platform device, if the previous
+// platform owner deleted or released the device or force is set. Returns the reason of the transfer or an empty string, if the device was not transferred.
+// The device counts as deleted, if the previous owner has no platform device with the dev eui anymore.
+func (c *Controller) transferDeviceIfReleased(ctx context.Context, platformDevice *models.ExtendedDevice, existing *api.Device, target *api.Device, force bool) (reason model.TransferReason, err error) {
+	app, err := c.chirpApp.Get(ctx, &api.GetApplicationRequest{Id: existing.ApplicationId})
+	if err != nil {
+		return "", err
+	}
+	previousOwnerId, err := c.getChirpstackTenantOwnerId(ctx, app.Application.TenantId)
+	if err != nil {
+		return "", err
+	}
+	if force {
+		reason = model.TransferReasonAdmin
+	} else if previousOwnerId != "" && previousOwnerId != platformDevice.OwnerId {
+		c.jwtMux.RLock()
+		previousDevices, err, _ := c.deviceRepo.ListDevices("Bearer "+c.jwt.AccessToken, device_repo.DeviceListOptions{
+			LocalIds: []string{existing.DevEui},
+			Owner:    previousOwnerId,
+		})
+		c.jwtMux.RUnlock()
+		if err != nil {
+			return "", err
+		}
+		if len(previousDevices) == 0 {
+			reason = model.TransferReasonDeleted
+		}
+		for _, previousDevice := range previousDevices {
+			if getAttributeValue(previousDevice.Attributes, model.DeviceAttributeReleasedKey) == "true" {
+				reason = model.TransferReasonReleased
+			}
+		}
+	}
+	if reason == "" {
+		return "", nil
+	}
+
+	target = proto.CloneOf(target)
+	if target.JoinEui == "" {
+		target.JoinEui = existing.JoinEui
+	}
+	err = c.moveChirpDevice(ctx, existing, target)
+	if err != nil {
+		return "", err
+	}
+	tenantId, err := c.getApplicationTenantId(ctx, target.ApplicationId)
+	if err != nil {
+		log.Logger.Error("unable to resolve tenant of transferred device", attributes.ErrorKey, err, "dev_eui", existing.DevEui)
+	}
+	c.recordTransfer(ctx, model.Transfer{
+		Type:         model.TransferTypeDevice,
+		Eui:          existing.DevEui,
+		PlatformId:   platformDevice.Id,
+		FromUserId:   previousOwnerId,
+		ToUserId:     platformDevice.OwnerId,
+		FromTenantId: app.Application.TenantId,
+		ToTenantId:   tenantId,
+		Reason:       reason,
+		Time:         time.Now(),
+	})
+	return reason, nil
+}
+
+// transferGatewayIfReleased moves an existing chirpstack gateway into the tenant of the hub owner, if the previous platform owner deleted
+// or released the hub or force is set. Returns the reason of the transfer or an empty string, if the gateway was not transferred.
+// The hub counts as deleted, if the previous owner has no hub with the gateway eui anymore.
+func (c *Controller) transferGatewayIfReleased(ctx context.Context, hub *models.Hub, existing *api.Gateway, tenantId string, force bool) (reason model.TransferReason, err error) {
+	previousOwnerId, err := c.getChirpstackTenantOwnerId(ctx, existing.TenantId)
+	if err != nil {
+		return "", err
+	}
+	if force {
+		reason = model.TransferReasonAdmin
+	} else if previousOwnerId != "" && previousOwnerId != hub.OwnerId {
+		previousHubs, err := c.listHubsOfOwnerByEui(previousOwnerId, existing.GatewayId)
+		if err != nil {
+			return "", err
+		}
+		if len(previousHubs) == 0 {
+			reason = model.TransferReasonDeleted
+		}
+		for _, previousHub := range previousHubs {
+			if getAttributeValue(previousHub.Attributes, model.DeviceAttributeReleasedKey) == "true" {
+				reason = model.TransferReasonReleased
+			}
+		}
+	}
+	if reason == "" {
+		return "", nil
+	}
+
+	target := proto.CloneOf(existing)
+	target.TenantId = tenantId
+	_, err = c.chirpGateway.Delete(ctx, &api.DeleteGatewayRequest{GatewayId: existing.GatewayId})
+	if err != nil {
+		return "", err
+	}
+	_, err = c.chirpGateway.Create(ctx, &api.CreateGatewayRequest{Gateway: target})
+	if err != nil {
+		_, restoreErr := c.chirpGateway.Create(ctx, &api.CreateGatewayRequest{Gateway: existing})
+		return "", errors.Join(err, restoreErr)
+	}
+	c.recordTransfer(ctx, model.Transfer{
+		Type:         model.TransferTypeGateway,
+		Eui:          existing.GatewayId,
+		PlatformId:   hub.Id,
+		FromUserId:   previousOwnerId,
+		ToUserId:     hub.OwnerId,
+		FromTenantId: existing.TenantId,
+		ToTenantId:   tenantId,
+		Reason:       reason,
+		Time:         time.Now(),
+	})
+	return reason, nil
+}
+
+// moveChirpDevice recreates the existing device as target, keeping its keys and activation.
+// Chirpstack does not support changing the application of a device.
+func (c *Controller) moveChirpDevice(ctx context.Context, existing *api.Device, target *api.Device) error {
+	keys, err := c.chirpDevice.GetKeys(ctx, &api.GetDeviceKeysRequest{DevEui: existing.DevEui})
+	if err != nil && status.Code(err) != codes.NotFound {
+		return err
+	}
+	activation, err := c.chirpDevice.GetActivation(ctx, &api.GetDeviceActivationRequest{DevEui: existing.DevEui})
+	if err != nil && status.Code(err) != codes.NotFound {
+		return err
+	}
+	_, err = c.chirpDevice.Delete(ctx, &api.DeleteDeviceRequest{DevEui: existing.DevEui})
+	if err != nil {
+		return err
+	}
+	err = c.createChirpDeviceWithState(ctx, target, keys, activation)
+	if err != nil {
+		log.Logger.Error("unable to move device, restoring previous device", attributes.ErrorKey, err, "dev_eui", existing.DevEui)
+		_, _ = c.chirpDevice.Delete(ctx, &api.DeleteDeviceRequest{DevEui: existing.DevEui})
+		return errors.Join(err, c.createChirpDeviceWithState(ctx, existing, keys, activation))
+	}
+	return nil
+}
+
+func (c *Controller) createChirpDeviceWithState(ctx context.Context, device *api.Device, keys *api.GetDeviceKeysResponse, activation *api.GetDeviceActivationResponse) error {
+	_, err := c.chirpDevice.Create(ctx, &api.CreateDeviceRequest{Device: device})
+	if err != nil {
+		return err
+	}
+	if keys != nil && keys.DeviceKeys != nil {
+		_, err = c.chirpDevice.CreateKeys(ctx, &api.CreateDeviceKeysRequest{DeviceKeys: keys.DeviceKeys})
+		if err != nil {
+			return err
+		}
+	}
+	if activation != nil && activation.DeviceActivation != nil {
+		_, err = c.chirpDevice.Activate(ctx, &api.ActivateDeviceRequest{DeviceActivation: activation.DeviceActivation})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getChirpstackTenantOwnerId returns the platform user of a tenant managed by the connector or an empty string for unmanaged tenants.
+func (c *Controller) getChirpstackTenantOwnerId(ctx context.Context, tenantId string) (string, error) {
+	tenant, err := c.chirpTenant.Get(ctx, &api.GetTenantRequest{Id: tenantId})
+	if err != nil {
+		return "", err
+	}
+	return tenant.Tenant.Tags[model.ChirpTagUserId], nil
+}
+
+func (c *Controller) getApplicationTenantId(ctx context.Context, appId string) (string, error) {
+	app, err := c.chirpApp.Get(ctx, &api.GetApplicationRequest{Id: appId})
+	if err != nil {
+		return "", err
+	}
+	return app.Application.TenantId, nil
+}
+
+// listHubsOfOwnerByEui lists the hubs of the owner with the gateway eui. The device repository can not filter hubs by owner or attribute,
+// so the hubs are listed with the token of the owner, which limits the listing to hubs the owner administrates.
+// Without the platform connector, all hubs are listed with the admin token.
+func (c *Controller) listHubsOfOwnerByEui(ownerId string, eui string) (result []models.Hub, err error) {
+	token, err := c.userToken(ownerId)
+	if errors.Is(err, errNoConnector) {
+		token, err = c.adminToken(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var limit int64 = 1000
+	var offset int64 = 0
+	for {
+		hubs, err, _ := c.deviceRepo.ListHubs(token, device_repo.HubListOptions{
+			Limit:      limit,
+			Offset:     offset,
+			Permission: models.Administrate,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, hub := range hubs {
+			if hub.OwnerId == ownerId && getAttributeValue(hub.Attributes, model.GatewayAttributeEUI) == eui {
+				result = append(result, hub)
+			}
+		}
+		if int64(len(hubs)) < limit {
+			return result, nil
+		}
+		offset += limit
+	}
+}
+
+func (c *Controller) recordTransfer(ctx context.Context, transfer model.Transfer) {
+	log.Logger.Info("transferred chirpstack "+transfer.Type, "eui", transfer.Eui, "from_user_id", transfer.FromUserId, "to_user_id", transfer.ToUserId, "reason", transfer.Reason)
+	b, err := json.Marshal(transfer)
+	if err != nil {
+		log.Logger.Error("unable to marshal transfer", attributes.ErrorKey, err)
+		return
+	}
+	_, err = c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, model.RedisKeyTransfers, b)
+		pipe.LTrim(ctx, model.RedisKeyTransfers, 0, transferLogLength-1)
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error("unable to record transfer", attributes.ErrorKey, err, "eui", transfer.Eui)
+	}
+}
+
+func getAttributeValue(attributes []models.Attribute, key string) string {
+	for _, a := range attributes {
+		if a.Key == key {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,282 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/platform-connector-lib/security"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/chirpstack/chirpstack/api/go/v4/gw"
+	"github.com/go-redis/redis/v8"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const uplinkBufferGroup = "lorawan-platform-connector"
+
+// ReceiveEvent persists the event to the uplink buffer, if enabled. Otherwise, the event is handled directly.
+func (c *Controller) ReceiveEvent(ctx context.Context, userId string, localDeviceId string, localServiceId string, payload any, ts time.Time, rxInfo []*gw.UplinkRxInfo, deviceProfileId string, deduplicationId string) error {
+	if !c.config.UplinkBuffer {
+		return c.HandleEvent(ctx, userId, localDeviceId, localServiceId, payload, ts, rxInfo, deviceProfileId, deduplicationId)
+	}
+	event := model.BufferedEvent{
+		UserId:          userId,
+		LocalDeviceId:   localDeviceId,
+		LocalServiceId:  localServiceId,
+		Time:            ts,
+		DeviceProfileId: deviceProfileId,
+		DeduplicationId: deduplicationId,
+	}
+	var err error
+	event.Payload, err = json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	for _, rx := range rxInfo {
+		b, err := protojson.Marshal(rx)
+		if err != nil {
+			return err
+		}
+		event.RxInfo = append(event.RxInfo, b)
+	}
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return c.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: model.RedisKeyUplinkBuffer,
+		Values: map[string]any{"event": string(b)},
+	}).Err()
+}
+
+func (c *Controller) handleBufferedEvent(ctx context.Context, event model.BufferedEvent) error {
+	rxInfo := []*gw.UplinkRxInfo{}
+	for _, raw := range event.RxInfo {
+		rx := &gw.UplinkRxInfo{}
+		err := protojson.Unmarshal(raw, rx)
+		if err != nil {
+			return err
+		}
+		rxInfo = append(rxInfo, rx)
+	}
+	return c.HandleEvent(ctx, event.UserId, event.LocalDeviceId, event.LocalServiceId, event.Payload, event.Time, rxInfo, event.DeviceProfileId, event.DeduplicationId)
+}
+
+func (c *Controller) setupUplinkBuffer(ctx context.Context) error {
+	err := c.rdb.XGroupCreateMkStream(ctx, model.RedisKeyUplinkBuffer, uplinkBufferGroup, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	for i := range c.config.UplinkBufferWorkers {
+		go c.runUplinkBufferWorker(ctx, fmt.Sprintf("%s-%d", hostname, i))
+	}
+	return nil
+}
+
+func (c *Controller) runUplinkBufferWorker(ctx context.Context, consumer string) {
+	// entries of stopped consumers are claimed after this idle time.
+	// workers reset the idle time of the entry they are working on after each attempt.
+	claimIdle := 2*c.config.UplinkBufferMaxBackoff + time.Minute
+	for ctx.Err() == nil {
+		messages, _, err := c.rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   model.RedisKeyUplinkBuffer,
+			Group:    uplinkBufferGroup,
+			Consumer: consumer,
+			MinIdle:  claimIdle,
+			Start:    "0-0",
+			Count:    1,
+		}).Result()
+		if err != nil && ctx.Err() == nil {
+			log.Logger.Error("unable to claim buffered uplinks", attributes.ErrorKey, err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		if len(messages) == 0 {
+			streams, err := c.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+				Group:    uplinkBufferGroup,
+				Consumer: consumer,
+				Streams:  []string{model.RedisKeyUplinkBuffer, ">"},
+				Count:    1,
+				Block:    5 * time.Second,
+			}).Result()
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Logger.Error("unable to read buffered uplinks", attributes.ErrorKey, err)
+					time.Sleep(5 * time.Second)
+				}
+				continue
+			}
+			for _, stream := range streams {
+				messages = append(messages, stream.Messages...)
+			}
+		}
+		for _, message := range messages {
+			c.processBufferedEvent(ctx, consumer, message)
+		}
+	}
+}
+
+// processBufferedEvent handles the entry with exponential backoff. Entries are moved to the dead letter stream
+// after the configured number of attempts or if the device is unknown. The entry stays pending, if ctx is done.
+func (c *Controller) processBufferedEvent(ctx context.Context, consumer string, message redis.XMessage) {
+	raw, _ := message.Values["event"].(string)
+	event := model.BufferedEvent{}
+	err := json.Unmarshal([]byte(raw), &event)
+	attempts := 0
+	backoff := time.Second
+	for err == nil {
+		attempts++
+		err = c.handleBufferedEvent(ctx, event)
+		if err == nil || errors.Is(err, security.ErrorNotFound) || attempts >= int(c.config.UplinkBufferMaxAttempts) {
+			break
+		}
+		log.Logger.Warn("unable to handle buffered uplink, retrying", attributes.ErrorKey, err, "dev_eui", event.LocalDeviceId, "attempt", attempts, "backoff", backoff.String())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, c.config.UplinkBufferMaxBackoff)
+		err = c.rdb.XClaimJustID(ctx, &redis.XClaimArgs{
+			Stream:   model.RedisKeyUplinkBuffer,
+			Group:    uplinkBufferGroup,
+			Consumer: consumer,
+			Messages: []string{message.ID},
+		}).Err()
+		if err != nil {
+			log.Logger.Error("unable to reset idle time of buffered uplink", attributes.ErrorKey, err, "id", message.ID)
+			err = nil
+		}
+	}
+	if err != nil {
+		log.Logger.Error("unable to handle buffered uplink, moving to dead letters", attributes.ErrorKey, err, "dev_eui", event.LocalDeviceId, "attempts", attempts)
+		err = c.rdb.XAdd(ctx, &redis.XAddArgs{
+			Stream: model.RedisKeyUplinkDeadLetters,
+			MaxLen: c.config.UplinkBufferDeadLetters,
+			Approx: true,
+			Values: map[string]any{
+				"event":     raw,
+				"error":     err.Error(),
+				"attempts":  attempts,
+				"failed_at": time.Now().Format(time.RFC3339Nano),
+			},
+		}).Err()
+		if err != nil {
+			// entry stays pending and is claimed again
+			log.Logger.Error("unable to store dead letter", attributes.ErrorKey, err, "id", message.ID)
+			return
+		}
+	}
+	_, err = c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAck(ctx, model.RedisKeyUplinkBuffer, uplinkBufferGroup, message.ID)
+		pipe.XDel(ctx, model.RedisKeyUplinkBuffer, message.ID)
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error("unable to acknowledge buffered uplink", attributes.ErrorKey, err, "id", message.ID)
+	}
+}
+
+func (c *Controller) ListUplinkDeadLetters(ctx context.Context, token jwt.Token, limit int64, offset int64) ([]model.DeadLetter, error) {
+	if !token.IsAdmin() {
+		return nil, errors.Join(model.ErrForbidden, fmt.Errorf("only admins may read dead letters"))
+	}
+	messages, err := c.rdb.XRangeN(ctx, model.RedisKeyUplinkDeadLetters, "-", "+", limit+offset).Result()
+	if err != nil {
+		return nil, err
+	}
+	result := []model.DeadLetter{}
+	for i := offset; i < int64(len(messages)); i++ {
+		result = append(result, parseDeadLetter(messages[i]))
+	}
+	return result, nil
+}
+
+// ReplayUplinkDeadLetters moves the dead letters with the ids back to the uplink buffer. All dead letters are replayed, if ids is empty.
+func (c *Controller) ReplayUplinkDeadLetters(ctx context.Context, token jwt.Token, ids []string) (result model.DeadLetterReplay, err error) {
+	if !token.IsAdmin() {
+		return result, errors.Join(model.ErrForbidden, fmt.Errorf("only admins may replay dead letters"))
+	}
+	var messages []redis.XMessage
+	if len(ids) == 0 {
+		messages, err = c.rdb.XRange(ctx, model.RedisKeyUplinkDeadLetters, "-", "+").Result()
+		if err != nil {
+			return result, err
+		}
+	} else {
+		for _, id := range ids {
+			found, err := c.rdb.XRange(ctx, model.RedisKeyUplinkDeadLetters, id, id).Result()
+			if err != nil {
+				return result, err
+			}
+			if len(found) == 0 {
+				return result, errors.Join(model.ErrNotFound, fmt.Errorf("dead letter %s not found", id))
+			}
+			messages = append(messages, found...)
+		}
+	}
+	for _, message := range messages {
+		_, err = c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.XAdd(ctx, &redis.XAddArgs{
+				Stream: model.RedisKeyUplinkBuffer,
+				Values: map[string]any{"event": message.Values["event"]},
+			})
+			pipe.XDel(ctx, model.RedisKeyUplinkDeadLetters, message.ID)
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+		result.Replayed++
+	}
+	return result, nil
+}
+
+func parseDeadLetter(message redis.XMessage) model.DeadLetter {
+	deadLetter := model.DeadLetter{Id: message.ID}
+	if raw, ok := message.Values["event"].(string); ok {
+		err := json.Unmarshal([]byte(raw), &deadLetter.Event)
+		if err != nil {
+			log.Logger.Warn("unable to parse dead letter", attributes.ErrorKey, err, "id", message.ID)
+		}
+	}
+	deadLetter.Error, _ = message.Values["error"].(string)
+	if attempts, ok := message.Values["attempts"].(string); ok {
+		deadLetter.Attempts, _ = strconv.Atoi(attempts)
+	}
+	if failedAt, ok := message.Values["failed_at"].(string); ok {
+		deadLetter.FailedAt, _ = time.Parse(time.RFC3339Nano, failedAt)
+	}
+	return deadLetter
+}
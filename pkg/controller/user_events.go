@@ -0,0 +1,286 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/platform-connector-lib/kafka"
+	"github.com/chirpstack/chirpstack/api/go/v4/api"
+	"github.com/go-redis/redis/v8"
+)
+
+func (c *Controller) setupEventSyncUser(ctx context.Context) error {
+	if c.config.KafkaBootstrap == "" || c.config.KafkaUserTopic == "" {
+		log.Logger.Warn("unable to setup kafka user sync: no kafka bootstrap or user topic defined")
+		return nil
+	}
+	return kafka.NewConsumer(ctx, kafka.ConsumerConfig{
+		KafkaUrl:         c.config.KafkaBootstrap,
+		GroupId:          "lorawan-platform-connector",
+		Topic:            c.config.KafkaUserTopic,
+		MaxWait:          time.Second,
+		MinBytes:         1000,
+		MaxBytes:         1000000,
+		InitTopic:        false,
+		AllowOldMessages: false,
+		Logger:           log.Logger,
+	}, func(_ string, msg []byte, _ time.Time) error {
+		var command model.UserCommand
+		err := json.Unmarshal(msg, &command)
+		if err != nil {
+			return err
+		}
+		ctx2, cf := context.WithTimeout(ctx, time.Minute)
+		defer cf()
+		switch command.Command {
+		case model.PutCommand:
+			return c.SyncUser(ctx2, command.Id)
+		case model.DeleteCommand:
+			return c.DeprovisionUser(ctx2, command.Id)
+		default:
+			return nil
+		}
+	}, func(err error) {
+		log.Logger.Error("kafka EventSyncUser error", attributes.ErrorKey, err)
+	})
+}
+
+// SyncUser provisions the keycloak user with the given id. If the email of the user changed, the chirpstack user and tenant are renamed first.
+func (c *Controller) SyncUser(ctx context.Context, userId string) error {
+	c.jwtMux.RLock()
+	user, err := c.gocloakClient.GetUserByID(ctx, c.jwt.AccessToken, c.config.KeycloakRealm, userId)
+	c.jwtMux.RUnlock()
+	if err != nil {
+		return errors.Join(fmt.Errorf("unable to read user from keycloak"), err)
+	}
+	if user.Email == nil || *user.Email == "" {
+		log.Logger.Warn("user has no email, skipping provisioning", "user_id", userId)
+		return nil
+	}
+	tenant, err := c.findChirpstackTenantOfUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if tenant != nil && tenant.Name != *user.Email {
+		err = c.renameChirpstackUser(ctx, tenant, *user.Email)
+		if err != nil {
+			return err
+		}
+	}
+	return c.ProvisionUser(ctx, "", model.UserInfoFromUser(user))
+}
+
+// DeprovisionUser deletes the chirpstack user and tenant of a deleted keycloak user and cleans up the related redis keys and cached tokens.
+func (c *Controller) DeprovisionUser(ctx context.Context, userId string) error {
+	tenant, err := c.findChirpstackTenantOfUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if tenant == nil {
+		log.Logger.Debug("no chirpstack tenant for deleted user", "user_id", userId)
+	} else if slices.Contains(c.config.ChirpstackProtectedUsers, tenant.Name) {
+		log.Logger.Warn("not deprovisioning protected user", "user_id", userId, "email", tenant.Name)
+		return nil
+	} else {
+		err = c.deleteTenantRedisKeys(ctx, tenant.Id)
+		if err != nil {
+			return err
+		}
+		err = c.DeleteUser(ctx, tenant.Name)
+		if err != nil {
+			return err
+		}
+		log.Logger.Info("deprovisioned user", "user_id", userId, "email", tenant.Name)
+	}
+	err = c.rdb.Del(ctx, fmt.Sprintf(model.RedisKeyFmtShares, userId), fmt.Sprintf(model.RedisKeyFmtUserEmail, userId)).Err()
+	if err != nil {
+		return err
+	}
+	err = c.deleteWebhooksOfUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	// the token cache of the connector lib shares the memcached instance with the iot cache
+	err = c.cache.Remove("token." + userId)
+	if err != nil {
+		log.Logger.Warn("unable to remove cached user token", attributes.ErrorKey, err, "user_id", userId)
+	}
+	return nil
+}
+
+// findChirpstackTenantOfUser returns the tenant tagged with the user id or nil. The email remembered during provisioning is checked first,
+// all tenants are searched for users provisioned before the email was remembered.
+func (c *Controller) findChirpstackTenantOfUser(ctx context.Context, userId string) (*api.Tenant, error) {
+	email, err := c.rdb.Get(ctx, fmt.Sprintf(model.RedisKeyFmtUserEmail, userId)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	if email != "" {
+		tenantList, err := c.chirpTenant.List(ctx, &api.ListTenantsRequest{Search: email, Limit: 10})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range tenantList.Result {
+			tenant, err := c.chirpTenant.Get(ctx, &api.GetTenantRequest{Id: item.Id})
+			if err != nil {
+				return nil, err
+			}
+			if tenant.Tenant.Tags[model.ChirpTagUserId] == userId {
+				return tenant.Tenant, nil
+			}
+		}
+	}
+	var limit uint32 = 1000
+	var offset uint32 = 0
+	for {
+		tenantList, err := c.chirpTenant.List(ctx, &api.ListTenantsRequest{Limit: limit, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range tenantList.Result {
+			tenant, err := c.chirpTenant.Get(ctx, &api.GetTenantRequest{Id: item.Id})
+			if err != nil {
+				return nil, err
+			}
+			if tenant.Tenant.Tags[model.ChirpTagUserId] == userId {
+				return tenant.Tenant, nil
+			}
+		}
+		if len(tenantList.Result) < int(limit) {
+			return nil, nil
+		}
+		offset += limit
+	}
+}
+
+func (c *Controller) renameChirpstackUser(ctx context.Context, tenant *api.Tenant, email string) error {
+	oldEmail := tenant.Name
+	var limit uint32 = 1000
+	var offset uint32 = 0
+	cont := true
+	for cont {
+		users, err := c.chirpUserClient.List(ctx, &api.ListUsersRequest{Limit: limit, Offset: offset})
+		if err != nil {
+			return err
+		}
+		for _, item := range users.GetResult() {
+			if item.Email != oldEmail {
+				continue
+			}
+			user, err := c.chirpUserClient.Get(ctx, &api.GetUserRequest{Id: item.Id})
+			if err != nil {
+				return err
+			}
+			user.User.Email = email
+			_, err = c.chirpUserClient.Update(ctx, &api.UpdateUserRequest{User: user.User})
+			if err != nil {
+				return err
+			}
+			cont = false
+			break
+		}
+		if len(users.GetResult()) < int(limit) {
+			break
+		}
+		offset += limit
+	}
+	tenant.Name = email
+	_, err := c.chirpTenant.Update(ctx, &api.UpdateTenantRequest{Tenant: tenant})
+	if err != nil {
+		return err
+	}
+	log.Logger.Info("renamed chirpstack user and tenant", "user_id", tenant.Tags[model.ChirpTagUserId], "old_email", oldEmail, "email", email)
+	return nil
+}
+
+// deleteTenantRedisKeys removes the gateway/device timestamps, claims and key rotations of all gateways and devices of the tenant.
+func (c *Controller) deleteTenantRedisKeys(ctx context.Context, tenantId string) error {
+	patterns := []string{}
+	var limit uint32 = 1000
+	var offset uint32 = 0
+	for {
+		gateways, err := c.chirpGateway.List(ctx, &api.ListGatewaysRequest{TenantId: tenantId, Limit: limit, Offset: offset})
+		if err != nil {
+			return err
+		}
+		for _, gateway := range gateways.Result {
+			patterns = append(patterns, fmt.Sprintf(model.RedisKeyFmtGatewayDevice, gateway.GatewayId, "*"))
+		}
+		if len(gateways.Result) < int(limit) {
+			break
+		}
+		offset += limit
+	}
+	keys := []string{}
+	offset = 0
+	for {
+		apps, err := c.chirpApp.List(ctx, &api.ListApplicationsRequest{TenantId: tenantId, Limit: limit, Offset: offset})
+		if err != nil {
+			return err
+		}
+		for _, app := range apps.Result {
+			var deviceOffset uint32 = 0
+			for {
+				devices, err := c.chirpDevice.List(ctx, &api.ListDevicesRequest{ApplicationId: app.Id, Limit: limit, Offset: deviceOffset})
+				if err != nil {
+					return err
+				}
+				for _, device := range devices.Result {
+					devEui := strings.ToLower(device.DevEui)
+					patterns = append(patterns, fmt.Sprintf(model.RedisKeyFmtGatewayDevice, "*", devEui))
+					keys = append(keys, fmt.Sprintf(model.RedisKeyFmtDeviceClaim, devEui), fmt.Sprintf(model.RedisKeyFmtKeyRotation, devEui))
+				}
+				if len(devices.Result) < int(limit) {
+					break
+				}
+				deviceOffset += limit
+			}
+		}
+		if len(apps.Result) < int(limit) {
+			break
+		}
+		offset += limit
+	}
+	for _, pattern := range patterns {
+		var cursor uint64
+		for {
+			found, nextCursor, err := c.rdb.Scan(ctx, cursor, pattern, 1000).Result()
+			if err != nil {
+				return err
+			}
+			keys = append(keys, found...)
+			cursor = nextCursor
+			if cursor == 0 {
+				break
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.rdb.Del(ctx, keys...).Err()
+}
@@ -0,0 +1,547 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/SENERGY-Platform/service-commons/pkg/cache"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/chirpstack/chirpstack/api/go/v4/gw"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+const maxWebhooksPerUser = 10
+const webhookDeliveryLogLength = 100
+const webhookQueueSize = 1000
+const webhookTimeout = 10 * time.Second
+const webhookMaxBackoff = 5 * time.Minute
+const webhookDeliveryLogTtl = 30 * 24 * time.Hour // delivery logs of deleted webhooks expire
+const webhookExistenceCacheTtl = 10 * time.Second // uplinks of users without webhooks skip reading the subscriptions
+const webhookCreateAttempts = 3                   // concurrent changes of the webhooks of a user abort the limit check
+
+var webhookEventTypes = []string{model.WebhookEventUp, model.WebhookEventJoin, model.WebhookEventStatus, model.WebhookEventLocation}
+
+type webhookDelivery struct {
+	userId       string
+	subscription model.WebhookSubscription
+	event        model.WebhookEvent
+	body         []byte
+	attempt      int
+}
+
+// ListWebhooks returns the webhook subscriptions of the user without their secrets.
+func (c *Controller) ListWebhooks(ctx context.Context, token jwt.Token) ([]model.WebhookSubscription, error) {
+	subscriptions, err := c.getWebhooks(ctx, token.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	return subscriptions, nil
+}
+
+// CreateWebhook adds a webhook subscription for the user. The returned subscription contains the secret used to sign deliveries.
+// The limit of webhooks per user is checked in a transaction, so concurrent requests can not exceed it.
+func (c *Controller) CreateWebhook(ctx context.Context, token jwt.Token, subscription model.WebhookSubscription) (model.WebhookSubscription, error) {
+	err := validateWebhook(subscription)
+	if err != nil {
+		return subscription, err
+	}
+	subscription.Id = uuid.NewString()
+	subscription.CreatedAt = time.Now().UTC()
+	if subscription.Secret == "" {
+		subscription.Secret, err = newWebhookSecret()
+		if err != nil {
+			return subscription, err
+		}
+	}
+	b, err := c.marshalWebhook(ctx, token.GetUserId(), subscription)
+	if err != nil {
+		return subscription, err
+	}
+	key := fmt.Sprintf(model.RedisKeyFmtWebhooks, token.GetUserId())
+	for range webhookCreateAttempts {
+		err = c.rdb.Watch(ctx, func(tx *redis.Tx) error {
+			count, err := tx.HLen(ctx, key).Result()
+			if err != nil {
+				return err
+			}
+			if count >= maxWebhooksPerUser {
+				return errors.Join(model.ErrBadRequest, fmt.Errorf("at most %d webhooks are allowed per user", maxWebhooksPerUser))
+			}
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.HSet(ctx, key, subscription.Id, b)
+				return nil
+			})
+			return err
+		}, key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			break
+		}
+	}
+	if errors.Is(err, redis.TxFailedErr) {
+		err = errors.Join(model.ErrConflict, fmt.Errorf("webhooks have been changed concurrently, please try again"))
+	}
+	if err != nil {
+		c.deleteWebhookSecret(ctx, token.GetUserId(), subscription.Id)
+		return subscription, err
+	}
+	c.forgetWebhookExistence(token.GetUserId())
+	return subscription, nil
+}
+
+// UpdateWebhook replaces the url and filters of a webhook subscription. The secret is kept, unless a new one is given.
+func (c *Controller) UpdateWebhook(ctx context.Context, token jwt.Token, id string, subscription model.WebhookSubscription) (model.WebhookSubscription, error) {
+	err := validateWebhook(subscription)
+	if err != nil {
+		return subscription, err
+	}
+	existing, err := c.getWebhook(ctx, token.GetUserId(), id)
+	if err != nil {
+		return subscription, err
+	}
+	subscription.Id = existing.Id
+	subscription.CreatedAt = existing.CreatedAt
+	if subscription.Secret == "" {
+		subscription.Secret = existing.Secret // empty, if the secret is kept in the secret store
+	}
+	err = c.setWebhook(ctx, token.GetUserId(), subscription)
+	subscription.Secret = ""
+	return subscription, err
+}
+
+// DeleteWebhook removes a webhook subscription and its delivery log.
+func (c *Controller) DeleteWebhook(ctx context.Context, token jwt.Token, id string) error {
+	_, err := c.getWebhook(ctx, token.GetUserId(), id)
+	if err != nil {
+		return err
+	}
+	_, err = c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, fmt.Sprintf(model.RedisKeyFmtWebhooks, token.GetUserId()), id)
+		pipe.Del(ctx, fmt.Sprintf(model.RedisKeyFmtWebhookDeliveries, id))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	c.deleteWebhookSecret(ctx, token.GetUserId(), id)
+	c.forgetWebhookExistence(token.GetUserId())
+	return nil
+}
+
+// ListWebhookDeliveries returns the delivery log of a webhook subscription, newest first.
+func (c *Controller) ListWebhookDeliveries(ctx context.Context, token jwt.Token, id string, limit int64, offset int64) ([]model.WebhookDelivery, error) {
+	_, err := c.getWebhook(ctx, token.GetUserId(), id)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := c.rdb.LRange(ctx, fmt.Sprintf(model.RedisKeyFmtWebhookDeliveries, id), offset, offset+limit-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]model.WebhookDelivery, 0, len(entries))
+	for _, entry := range entries {
+		var delivery model.WebhookDelivery
+		err = json.Unmarshal([]byte(entry), &delivery)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, delivery)
+	}
+	return result, nil
+}
+
+func validateWebhook(subscription model.WebhookSubscription) error {
+	u, err := url.Parse(subscription.Url)
+	if err != nil {
+		return errors.Join(model.ErrBadRequest, fmt.Errorf("invalid webhook url"), err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Join(model.ErrBadRequest, fmt.Errorf("webhook url must be an absolute http or https url"))
+	}
+	for _, event := range subscription.Events {
+		if !slices.Contains(webhookEventTypes, event) {
+			return errors.Join(model.ErrBadRequest, fmt.Errorf("unknown webhook event %s", event))
+		}
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (c *Controller) getWebhooks(ctx context.Context, userId string) ([]model.WebhookSubscription, error) {
+	entries, err := c.rdb.HGetAll(ctx, fmt.Sprintf(model.RedisKeyFmtWebhooks, userId)).Result()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]model.WebhookSubscription, 0, len(entries))
+	for _, entry := range entries {
+		var subscription model.WebhookSubscription
+		err = json.Unmarshal([]byte(entry), &subscription)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, subscription)
+	}
+	slices.SortFunc(result, func(a, b model.WebhookSubscription) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return result, nil
+}
+
+func (c *Controller) getWebhook(ctx context.Context, userId string, id string) (subscription model.WebhookSubscription, err error) {
+	entry, err := c.rdb.HGet(ctx, fmt.Sprintf(model.RedisKeyFmtWebhooks, userId), id).Result()
+	if errors.Is(err, redis.Nil) {
+		return subscription, errors.Join(model.ErrNotFound, fmt.Errorf("webhook %s not found", id))
+	}
+	if err != nil {
+		return subscription, err
+	}
+	err = json.Unmarshal([]byte(entry), &subscription)
+	return subscription, err
+}
+
+func (c *Controller) setWebhook(ctx context.Context, userId string, subscription model.WebhookSubscription) error {
+	b, err := c.marshalWebhook(ctx, userId, subscription)
+	if err != nil {
+		return err
+	}
+	err = c.rdb.HSet(ctx, fmt.Sprintf(model.RedisKeyFmtWebhooks, userId), subscription.Id, b).Err()
+	if err != nil {
+		return err
+	}
+	c.forgetWebhookExistence(userId)
+	return nil
+}
+
+// deleteWebhooksOfUser removes all webhook subscriptions and delivery logs of a deprovisioned user.
+func (c *Controller) deleteWebhooksOfUser(ctx context.Context, userId string) error {
+	key := fmt.Sprintf(model.RedisKeyFmtWebhooks, userId)
+	ids, err := c.rdb.HKeys(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	keys := []string{key}
+	for _, id := range ids {
+		keys = append(keys, fmt.Sprintf(model.RedisKeyFmtWebhookDeliveries, id))
+	}
+	err = c.rdb.Del(ctx, keys...).Err()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		c.deleteWebhookSecret(ctx, model.AttributeOrigin, id)
+	}
+	c.forgetWebhookExistence(userId)
+	return nil
+}
+
+// marshalWebhook returns the subscription as stored in redis. With a secret store, a given secret is moved to the store
+// and the secret in redis is left empty. Secrets stored in redis before the secret store was configured are moved on the next update.
+func (c *Controller) marshalWebhook(ctx context.Context, actor string, subscription model.WebhookSubscription) ([]byte, error) {
+	if c.secrets != nil && subscription.Secret != "" {
+		err := c.secrets.Put(ctx, fmt.Sprintf(model.SecretIdFmtWebhook, subscription.Id), []byte(subscription.Secret), actor)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("unable to store webhook secret"), err)
+		}
+		subscription.Secret = ""
+	}
+	return json.Marshal(subscription)
+}
+
+// webhookSecret returns the secret of the subscription, which is read from the secret store, if it is not stored in redis.
+func (c *Controller) webhookSecret(ctx context.Context, subscription model.WebhookSubscription) (string, error) {
+	if subscription.Secret != "" {
+		return subscription.Secret, nil
+	}
+	if c.secrets == nil {
+		return "", fmt.Errorf("secret of webhook %s is kept in the secret store, which is not configured", subscription.Id)
+	}
+	secret, err := c.secrets.Get(ctx, fmt.Sprintf(model.SecretIdFmtWebhook, subscription.Id), model.AttributeOrigin)
+	if err != nil {
+		return "", errors.Join(fmt.Errorf("unable to read webhook secret"), err)
+	}
+	return string(secret), nil
+}
+
+func (c *Controller) deleteWebhookSecret(ctx context.Context, actor string, id string) {
+	if c.secrets == nil {
+		return
+	}
+	err := c.secrets.Delete(ctx, fmt.Sprintf(model.SecretIdFmtWebhook, id), actor)
+	if err != nil {
+		log.Logger.Error("unable to delete webhook secret", attributes.ErrorKey, err, "webhook_id", id)
+	}
+}
+
+// setupWebhooks starts the workers delivering events to user webhooks. Deliveries are kept in memory and are lost on restart.
+func (c *Controller) setupWebhooks(ctx context.Context) {
+	dialer := &net.Dialer{Timeout: webhookTimeout}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !c.config.WebhookAllowPrivateNet {
+		// a proxy would connect to the webhook on our behalf and bypass the address check
+		transport.Proxy = nil
+		dialer.Control = denyPrivateNetworks
+	}
+	transport.DialContext = dialer.DialContext
+	c.webhookClient = &http.Client{
+		Timeout:   webhookTimeout,
+		Transport: transport,
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	c.webhookQueue = make(chan webhookDelivery, webhookQueueSize)
+	for range c.config.WebhookWorkers {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case delivery := <-c.webhookQueue:
+					c.deliverWebhook(ctx, delivery)
+				}
+			}
+		}()
+	}
+}
+
+func denyPrivateNetworks(_ string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("webhook address %s is not allowed", host)
+	}
+	return nil
+}
+
+func newWebhookEvent(eventType string, device models.Device, service models.Service, ts time.Time, payload json.RawMessage, rxInfo []*gw.UplinkRxInfo) model.WebhookEvent {
+	event := model.WebhookEvent{
+		Id:             uuid.NewString(),
+		Type:           eventType,
+		Time:           ts,
+		DeviceId:       device.Id,
+		DeviceName:     device.Name,
+		LocalDeviceId:  device.LocalId,
+		ServiceId:      service.Id,
+		LocalServiceId: service.LocalId,
+		Payload:        payload,
+	}
+	for _, rx := range rxInfo {
+		event.RxInfo = append(event.RxInfo, model.WebhookRxInfo{
+			GatewayId: rx.GatewayId,
+			Rssi:      rx.Rssi,
+			Snr:       rx.Snr,
+			Channel:   rx.Channel,
+		})
+	}
+	return event
+}
+
+// publishWebhookEvent queues the event for all matching webhooks of the user. Errors are only logged, webhooks never fail the event handling.
+func (c *Controller) publishWebhookEvent(userId string, event model.WebhookEvent) {
+	if c.webhookQueue == nil {
+		return
+	}
+	ctx, cf := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cf()
+	hasWebhooks, err := cache.Use(c.cache, webhookExistenceCacheKey(userId), func() (bool, error) {
+		count, err := c.rdb.HLen(ctx, fmt.Sprintf(model.RedisKeyFmtWebhooks, userId)).Result()
+		return count > 0, err
+	}, nil, webhookExistenceCacheTtl)
+	if err != nil {
+		log.Logger.Error("unable to check webhooks", attributes.ErrorKey, err, "user_id", userId)
+		return
+	}
+	if !hasWebhooks {
+		return
+	}
+	subscriptions, err := c.getWebhooks(ctx, userId)
+	if err != nil {
+		log.Logger.Error("unable to read webhooks", attributes.ErrorKey, err, "user_id", userId)
+		return
+	}
+	var body []byte
+	for _, subscription := range subscriptions {
+		if !webhookMatches(subscription, event) {
+			continue
+		}
+		if body == nil {
+			body, err = json.Marshal(event)
+			if err != nil {
+				log.Logger.Error("unable to marshal webhook event", attributes.ErrorKey, err, "user_id", userId)
+				return
+			}
+		}
+		c.queueWebhookDelivery(webhookDelivery{userId: userId, subscription: subscription, event: event, body: body, attempt: 1})
+	}
+}
+
+func webhookMatches(subscription model.WebhookSubscription, event model.WebhookEvent) bool {
+	return (len(subscription.Events) == 0 || slices.Contains(subscription.Events, event.Type)) &&
+		(len(subscription.DeviceIds) == 0 || slices.Contains(subscription.DeviceIds, event.DeviceId))
+}
+
+func webhookExistenceCacheKey(userId string) string {
+	return "lpc_has_webhooks_" + userId
+}
+
+// forgetWebhookExistence invalidates the cached existence check. Errors, like misses of an external cache, are ignored, the entry expires shortly anyway.
+func (c *Controller) forgetWebhookExistence(userId string) {
+	_ = c.cache.Remove(webhookExistenceCacheKey(userId))
+}
+
+func (c *Controller) queueWebhookDelivery(delivery webhookDelivery) {
+	select {
+	case c.webhookQueue <- delivery:
+	default:
+		log.Logger.Warn("webhook queue is full, dropping delivery", "webhook_id", delivery.subscription.Id, "event_id", delivery.event.Id)
+		c.logWebhookDelivery(delivery, 0, errors.New("webhook queue is full"))
+	}
+}
+
+// deliverWebhook posts the event once. Failed deliveries are queued again after an exponential backoff, without blocking the worker.
+// The subscription is read again before each attempt, so deliveries to deleted or changed webhooks are dropped or use the current url and secret.
+func (c *Controller) deliverWebhook(ctx context.Context, delivery webhookDelivery) {
+	readCtx, cf := context.WithTimeout(ctx, webhookTimeout)
+	subscription, err := c.getWebhook(readCtx, delivery.userId, delivery.subscription.Id)
+	cf()
+	if errors.Is(err, model.ErrNotFound) || (err == nil && !webhookMatches(subscription, delivery.event)) {
+		log.Logger.Debug("dropping delivery of removed or changed webhook", "webhook_id", delivery.subscription.Id, "user_id", delivery.userId, "event_id", delivery.event.Id)
+		return
+	}
+	if err == nil {
+		readCtx, cf = context.WithTimeout(ctx, webhookTimeout)
+		subscription.Secret, err = c.webhookSecret(readCtx, subscription)
+		cf()
+	}
+	statusCode := 0
+	if err == nil {
+		delivery.subscription = subscription
+		statusCode, err = c.postWebhook(ctx, delivery)
+	}
+	c.logWebhookDelivery(delivery, statusCode, err)
+	if err == nil {
+		return
+	}
+	// client errors will not resolve by retrying, except for timeouts and rate limits
+	retryable := statusCode < 400 || statusCode >= 500 || statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests
+	if !retryable || delivery.attempt >= int(c.config.WebhookMaxAttempts) {
+		log.Logger.Warn("webhook delivery failed", attributes.ErrorKey, err, "webhook_id", delivery.subscription.Id, "user_id", delivery.userId, "event_id", delivery.event.Id, "attempts", delivery.attempt)
+		return
+	}
+	backoff := min(time.Second<<(delivery.attempt-1), webhookMaxBackoff)
+	delivery.attempt++
+	time.AfterFunc(backoff, func() {
+		if ctx.Err() == nil {
+			c.queueWebhookDelivery(delivery)
+		}
+	})
+}
+
+// postWebhook signs the body with the secret of the subscription. Receivers verify the X-Webhook-Signature header
+// "sha256=<hex encoded hmac-sha256 of X-Webhook-Timestamp + "." + body>".
+func (c *Controller) postWebhook(ctx context.Context, delivery webhookDelivery) (statusCode int, err error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(delivery.subscription.Secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(delivery.body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.subscription.Url, bytes.NewReader(delivery.body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Id", delivery.event.Id)
+	req.Header.Set("X-Webhook-Event", delivery.event.Type)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	resp, err := c.webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode > 299 {
+		temp, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d from webhook: %s", resp.StatusCode, string(temp))
+	}
+	return resp.StatusCode, nil
+}
+
+func (c *Controller) logWebhookDelivery(delivery webhookDelivery, statusCode int, err error) {
+	entry := model.WebhookDelivery{
+		Time:       time.Now().UTC(),
+		EventId:    delivery.event.Id,
+		EventType:  delivery.event.Type,
+		DeviceId:   delivery.event.DeviceId,
+		Attempt:    delivery.attempt,
+		StatusCode: statusCode,
+		Success:    err == nil,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		log.Logger.Error("unable to marshal webhook delivery", attributes.ErrorKey, err)
+		return
+	}
+	ctx, cf := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cf()
+	key := fmt.Sprintf(model.RedisKeyFmtWebhookDeliveries, delivery.subscription.Id)
+	_, err = c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, key, b)
+		pipe.LTrim(ctx, key, 0, webhookDeliveryLogLength-1)
+		pipe.Expire(ctx, key, webhookDeliveryLogTtl)
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error("unable to log webhook delivery", attributes.ErrorKey, err, "webhook_id", delivery.subscription.Id)
+	}
+}
@@ -22,6 +22,36 @@ const ProtocolSegmentFPort = "fPort"
 const DeviceTypeAttributeManagedByKey = "senergy/managed-by"
 const DeviceTypeAttributeManagedByValue = "lorawan-platform-connector"
 const DeviceTypeAttributeDeviceProfileIdKey = "senergy/lora/device-profile-id"
+const DeviceTypeAttributeVendorKey = "senergy/lora/vendor"
+const DeviceTypeAttributeModelKey = "senergy/lora/model"
+const DeviceTypeAttributeRejoinFPortKey = "senergy/lora/rejoin-f-port"
+const DeviceProfileTagCommands = "senergy/lora/commands" // json list of downlink commands, overrides the command catalog file
+const CommandServiceLocalIdPrefix = "cmd:"
+const DeviceProfileTagLocationEstimator = "senergy/lora/location-estimator"            // "centroid" or "multilateration", publishes positions estimated from the receiving gateways as location service
+const DeviceProfileTagLocationUpdateDistance = "senergy/lora/location-update-distance" // meters an estimated position has to move to update the device location attributes, not updated if empty
+const DeviceTypeAttributeLocationEstimatorKey = DeviceProfileTagLocationEstimator
+const DeviceTypeAttributeLocationUpdateDistanceKey = DeviceProfileTagLocationUpdateDistance
+const LocationServiceLocalId = "location"
+const DeviceTypeAttributeRejoinPayloadKey = "senergy/lora/rejoin-payload" // hex encoded vendor specific downlink, which forces the device to rejoin
+
+const IngestionModeHttp = "http"   // chirpstack calls the event endpoint through a http integration per tenant
+const IngestionModeRedis = "redis" // device events are read from the redis event stream of chirpstack
+const IngestionModeMqtt = "mqtt"   // device events are read from the mqtt integration of chirpstack
+
+const EventSinkConnector = "connector" // events are sent to the platform, requires kafka
+const EventSinkFile = "file"           // events are written as json lines to a file or stdout
+const EventSinkWebhook = "webhook"     // events are posted as json
+
+const WebhookEventUp = "up"
+const WebhookEventJoin = "join"
+const WebhookEventStatus = "status"
+const WebhookEventLocation = "location" // positions estimated from the receiving gateways, see DeviceProfileTagLocationEstimator
+
+const LocationEstimatorCentroid = "centroid"               // rssi weighted centroid of the receiving gateways
+const LocationEstimatorMultilateration = "multilateration" // least squares fit of distances derived from rssi, falls back to the centroid with less than 3 gateways
+
+const EventEncodingJson = "json"
+const EventEncodingProtobuf = "protobuf"
 
 const DeviceAttributeDevAddrKey = "senergy/lora/dev-addr"
 const DeviceAttributeAppKey = "senergy/lora/app-key"
@@ -34,20 +64,59 @@ const DeviceAttributeFNwkSIntKey = "senergy/lora/f-nwk-s-int-key"
 const DeviceAttributeJoinEuiKey = "senergy/lora/join-eui"
 const DeviceAttributeJoinedKey = "senergy/lora/joined"
 const DeviceAttributeDuplicateKey = "senergy/lora/duplicate"
+const DeviceAttributeReleasedKey = "senergy/lora/released"
+const DeviceAttributeTransferredAtKey = "senergy/lora/transferred-at"
+const DeviceAttributeAdoptedAtKey = "senergy/lora/adopted-at"
+const DeviceKeyRedactedPrefix = "redacted:"
+const DeviceKeySecretPrefix = "secret:"
 const DeviceAttributeSupportsOTAAKey = "senergy/lora/supports-otaa"
 const DeviceAttributeSupportsClassBKey = "senergy/lora/supports-class-b"
 const DeviceAttributeSupportsClassCKey = "senergy/lora/supports-class-c"
 const DeviceAttributeMessageMaxAgeKey = "last_message_max_age"
+const DeviceAttributeLat = GatewayAttributeLat
+const DeviceAttributeLon = GatewayAttributeLon
 
 const GatewayAttributeEUI = "senergy/lora/eui"
 const GatewayAttributeLat = "location-lat"
 const GatewayAttributeLon = "location-lon"
 const GatewayAttributeCertsExpiration = "senergy/lora/certs-expiration"
+const GatewayAttributeTransferredAt = DeviceAttributeTransferredAtKey
+const GatewayAttributeAdoptedAt = DeviceAttributeAdoptedAtKey
+const GatewayAttributeCertsSecret = "senergy/lora/certs-secret"
 
 const AttributeOrigin = "lorawan-platform-connector"
 const AttributeOriginWebUI = "web-ui"
+const AttributeOriginChirpstack = "chirpstack" // set on attributes, which were edited in the chirpstack ui
+
+const AttributeDescriptionKey = "senergy/lora/description"
+
+const ManagedKeysKindDevice = "device"
+const ManagedKeysKindGateway = "gateway"
 
 const RedisPrefix = "lorawan-platform-connector_"
 const RedisKeyFmtGatewayDevice = RedisPrefix + "gateway_%s_%s"
+const RedisKeyFmtDeviceImportJob = RedisPrefix + "import_%s"
+const RedisKeyTransfers = RedisPrefix + "transfers"
+const RedisKeyFmtDeviceClaim = RedisPrefix + "claim_%s"
+const RedisKeyFmtKeyRotation = RedisPrefix + "key-rotation_%s"
+const RedisKeyFmtShares = RedisPrefix + "shares_%s"                        // hash of <topic>/<resource id>/<user id> -> share level per owner
+const RedisKeyFmtShareOwner = RedisPrefix + "share-owner_%s_%s"            // owner of a shared resource by topic and id
+const RedisKeyFmtUserEmail = RedisPrefix + "user-email_%s"                 // email of a provisioned user, which names the chirpstack user and tenant
+const RedisKeyKeycloakUserCount = RedisPrefix + "keycloak-user-count"      // user count of the last listing accepted for deletions
+const RedisKeyUplinkBuffer = RedisPrefix + "uplinks"                       // stream of received uplinks, consumed by the uplink buffer workers
+const RedisKeyUplinkDeadLetters = RedisPrefix + "uplinks-dead"             // stream of uplinks, which failed after all retries
+const RedisKeyFmtDeduplication = RedisPrefix + "dedup_%s_%s"               // processed chirpstack deduplication id by local service id
+const RedisKeyFmtWebhooks = RedisPrefix + "webhooks_%s"                    // hash of webhook id -> webhook subscription per user
+const RedisKeyFmtWebhookDeliveries = RedisPrefix + "webhook-deliveries_%s" // delivery log per webhook subscription, newest first
+const RedisKeyFmtManagedKeys = RedisPrefix + "managed-keys_%s_%s"          // tags and variables set from attributes by kind (device or gateway) and eui
+const RedisKeyPrefixSecret = RedisPrefix + "secret_"
+const RedisKeySecretAudit = RedisPrefix + "secret-audit"
+
+const SecretIdFmtDeviceKey = "devices/%s/%s"
+const SecretIdFmtGatewayCert = "gateways/%s/cert"
+const SecretIdFmtWebhook = "webhooks/%s"
+
+const PermissionsTopicDevices = "devices"
+const PermissionsTopicHubs = "hubs"
 
 const ChirpTagUserId = "userId"
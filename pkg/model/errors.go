@@ -25,6 +25,7 @@ import (
 var ErrBadRequest = fmt.Errorf("bad request")
 var ErrNotFound = fmt.Errorf("not found")
 var ErrForbidden = fmt.Errorf("forbidden")
+var ErrConflict = fmt.Errorf("conflict")
 
 func GetStatusCode(err error) int {
 	if err == nil {
@@ -39,5 +40,8 @@ func GetStatusCode(err error) int {
 	if errors.Is(err, ErrForbidden) {
 		return http.StatusForbidden
 	}
+	if errors.Is(err, ErrConflict) {
+		return http.StatusConflict
+	}
 	return http.StatusInternalServerError
 }
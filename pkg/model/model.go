@@ -17,6 +17,7 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/Nerzal/gocloak/v13"
@@ -60,6 +61,11 @@ type DeviceCommand struct {
 	Device  models.Device `json:"device"`
 }
 
+type UserCommand struct {
+	Command Command `json:"command"`
+	Id      string  `json:"id"`
+}
+
 type HubCommand struct {
 	Command Command    `json:"command"`
 	Id      string     `json:"id"`
@@ -71,3 +77,356 @@ type Certs struct {
 	Key         string    `json:"key"`
 	ExpiresAt   time.Time `json:"expires_at"`
 }
+
+type DeviceLoraStatus struct {
+	DeviceId     string                  `json:"device_id"`
+	DevEui       string                  `json:"dev_eui"`
+	Joined       bool                    `json:"joined"`
+	Duplicate    bool                    `json:"duplicate"`
+	Registered   bool                    `json:"registered"`
+	LastSeenAt   *time.Time              `json:"last_seen_at,omitempty"`
+	ClassEnabled string                  `json:"class_enabled,omitempty"`
+	DeviceStatus *DeviceLoraDeviceStatus `json:"device_status,omitempty"`
+	Activation   *DeviceLoraActivation   `json:"activation,omitempty"`
+	Gateways     []DeviceLoraGateway     `json:"gateways"`
+}
+
+type DeviceLoraDeviceStatus struct {
+	Margin              int32   `json:"margin"`
+	ExternalPowerSource bool    `json:"external_power_source"`
+	BatteryLevel        float32 `json:"battery_level"` // -1 if unavailable
+}
+
+type DeviceLoraActivation struct {
+	DevAddr   string `json:"dev_addr"`
+	FCntUp    uint32 `json:"f_cnt_up"`
+	NFCntDown uint32 `json:"n_f_cnt_down"`
+	AFCntDown uint32 `json:"a_f_cnt_down"`
+}
+
+type DeviceLoraGateway struct {
+	GatewayId  string    `json:"gateway_id"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+const (
+	DeviceValidationFieldLocalId         = "local_id"
+	DeviceValidationFieldName            = "name"
+	DeviceValidationFieldDeviceTypeId    = "device_type_id"
+	DeviceValidationFieldDeviceProfileId = "device_profile_id"
+)
+
+type DeviceValidationError struct {
+	Field   string `json:"field"` // attribute key or device field
+	Message string `json:"message"`
+}
+
+type DeviceValidationResult struct {
+	Valid    bool                    `json:"valid"`
+	Errors   []DeviceValidationError `json:"errors"`
+	Warnings []DeviceValidationError `json:"warnings"`
+}
+
+type DeviceImportRow struct {
+	DevEui          string `json:"dev_eui"`
+	JoinEui         string `json:"join_eui"`
+	AppKey          string `json:"app_key"`
+	NwkKey          string `json:"nwk_key"`
+	DevAddr         string `json:"dev_addr"`
+	AppSKey         string `json:"app_s_key"`
+	NwkSEncKey      string `json:"nwk_s_enc_key"`
+	SNwkSIntKey     string `json:"s_nwk_s_int_key"`
+	FNwkSIntKey     string `json:"f_nwk_s_int_key"`
+	DeviceProfileId string `json:"device_profile_id"`
+	Name            string `json:"name"`
+}
+
+type DeviceImportJobStatus = string
+
+const (
+	DeviceImportJobStatusRejected DeviceImportJobStatus = "rejected"
+	DeviceImportJobStatusRunning  DeviceImportJobStatus = "running"
+	DeviceImportJobStatusDone     DeviceImportJobStatus = "done"
+)
+
+type DeviceImportResultStatus = string
+
+const (
+	DeviceImportResultStatusPending DeviceImportResultStatus = "pending"
+	DeviceImportResultStatusInvalid DeviceImportResultStatus = "invalid"
+	DeviceImportResultStatusCreated DeviceImportResultStatus = "created"
+	DeviceImportResultStatusFailed  DeviceImportResultStatus = "failed"
+)
+
+type DeviceImportJob struct {
+	Id         string                `json:"id"`
+	UserId     string                `json:"user_id"`
+	Status     DeviceImportJobStatus `json:"status"`
+	CreatedAt  time.Time             `json:"created_at"`
+	FinishedAt *time.Time            `json:"finished_at,omitempty"`
+	Results    []DeviceImportResult  `json:"results"`
+}
+
+type DeviceImportResult struct {
+	Row      int                      `json:"row"` // 1-based index in the manifest, excluding a csv header
+	DevEui   string                   `json:"dev_eui"`
+	DeviceId string                   `json:"device_id,omitempty"`
+	Status   DeviceImportResultStatus `json:"status"`
+	Errors   []DeviceValidationError  `json:"errors"`
+	Error    string                   `json:"error,omitempty"`
+}
+
+type TransferType = string
+
+const (
+	TransferTypeDevice  TransferType = "device"
+	TransferTypeGateway TransferType = "gateway"
+)
+
+type TransferReason = string
+
+const (
+	TransferReasonDeleted  TransferReason = "deleted"  // previous owner deleted the platform device or hub
+	TransferReasonReleased TransferReason = "released" // previous owner set the released attribute
+	TransferReasonAdmin    TransferReason = "admin"    // transfer was approved by an admin
+	TransferReasonClaimed  TransferReason = "claimed"  // device was claimed from the inventory with a claim code
+)
+
+type Transfer struct {
+	Type         TransferType   `json:"type"`
+	Eui          string         `json:"eui"`
+	PlatformId   string         `json:"platform_id"`
+	FromUserId   string         `json:"from_user_id"`
+	ToUserId     string         `json:"to_user_id"`
+	FromTenantId string         `json:"from_tenant_id"`
+	ToTenantId   string         `json:"to_tenant_id"`
+	Reason       TransferReason `json:"reason"`
+	Time         time.Time      `json:"time"`
+}
+
+// DeviceClaim is stored for each inventory device with an active claim code. Only the hash of the code is kept.
+type DeviceClaim struct {
+	DeviceId  string    `json:"device_id"`
+	DevEui    string    `json:"dev_eui"`
+	CodeHash  string    `json:"code_hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DeviceClaimCode struct {
+	DeviceId  string    `json:"device_id"`
+	DevEui    string    `json:"dev_eui"`
+	ClaimCode string    `json:"claim_code"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DeviceClaimRequest struct {
+	DevEui    string `json:"dev_eui"`
+	ClaimCode string `json:"claim_code"`
+}
+
+type DeviceClaimResult struct {
+	DeviceId string `json:"device_id"`
+	DevEui   string `json:"dev_eui"`
+}
+
+// DeviceKeys are written to chirpstack only. Empty keys remain unchanged.
+type DeviceKeys struct {
+	AppKey      string `json:"app_key,omitempty"`
+	GenAppKey   string `json:"gen_app_key,omitempty"`
+	NwkKey      string `json:"nwk_key,omitempty"`
+	AppSKey     string `json:"app_s_key,omitempty"`
+	NwkSEncKey  string `json:"nwk_s_enc_key,omitempty"`
+	SNwkSIntKey string `json:"s_nwk_s_int_key,omitempty"`
+	FNwkSIntKey string `json:"f_nwk_s_int_key,omitempty"`
+}
+
+type SecretAction = string
+
+const (
+	SecretActionPut    SecretAction = "put"
+	SecretActionGet    SecretAction = "get"
+	SecretActionDelete SecretAction = "delete"
+	SecretActionRotate SecretAction = "rotate"
+)
+
+type SecretAuditEntry struct {
+	Time        time.Time    `json:"time"`
+	Action      SecretAction `json:"action"`
+	SecretId    string       `json:"secret_id"`
+	Actor       string       `json:"actor"`
+	MasterKeyId string       `json:"master_key_id"`
+}
+
+type SecretRotation struct {
+	Rotated int `json:"rotated"`
+}
+
+// GatewayCertMetadata is kept in the secret store for each issued gateway certificate. The private key is never stored.
+type GatewayCertMetadata struct {
+	HubId       string    `json:"hub_id"`
+	GatewayEui  string    `json:"gateway_eui"`
+	IssuedAt    time.Time `json:"issued_at"`
+	IssuedBy    string    `json:"issued_by"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Fingerprint string    `json:"fingerprint"` // sha256 of the PEM encoded certificate
+}
+
+type KeyRotationRequest struct {
+	AppKey        string `json:"app_key,omitempty"` // generated, if empty
+	NwkKey        string `json:"nwk_key,omitempty"` // generated, if empty
+	Rejoin        bool   `json:"rejoin"`
+	RejoinFPort   uint32 `json:"rejoin_f_port,omitempty"`  // defaults to the device type attribute senergy/lora/rejoin-f-port
+	RejoinPayload string `json:"rejoin_payload,omitempty"` // hex encoded, defaults to the device type attribute senergy/lora/rejoin-payload
+}
+
+type KeyRotationStatus = string
+
+const (
+	KeyRotationStatusPending   KeyRotationStatus = "pending"   // waiting for the device to rejoin
+	KeyRotationStatusConfirmed KeyRotationStatus = "confirmed" // device joined with the new keys
+)
+
+type KeyRotation struct {
+	DeviceId         string            `json:"device_id"`
+	DevEui           string            `json:"dev_eui"`
+	Status           KeyRotationStatus `json:"status"`
+	RequestedAt      time.Time         `json:"requested_at"`
+	RequestedBy      string            `json:"requested_by"`
+	RejoinDownlinkId string            `json:"rejoin_downlink_id,omitempty"`
+	ConfirmedAt      *time.Time        `json:"confirmed_at,omitempty"`
+}
+
+type KeyRotationResult struct {
+	KeyRotation
+	GeneratedKeys DeviceKeys `json:"generated_keys"` // supplied keys are not returned
+}
+
+type DownlinkEncoder = string
+
+const (
+	DownlinkEncoderCodec  DownlinkEncoder = "codec"  // the inputs and the command name (key "command") are encoded by the codec of the device profile
+	DownlinkEncoderHex    DownlinkEncoder = "hex"    // the input "payload" contains the hex encoded bytes
+	DownlinkEncoderBase64 DownlinkEncoder = "base64" // the input "payload" contains the base64 encoded bytes
+	DownlinkEncoderStatic DownlinkEncoder = "static" // the hex encoded payload of the command is sent, the command has no inputs
+)
+
+// CommandCatalog defines downlink commands per device profile. Keys are device profile ids or names.
+type CommandCatalog struct {
+	DeviceProfiles map[string][]DownlinkCommand `json:"device_profiles"`
+}
+
+type DownlinkCommand struct {
+	Name        string                 `json:"name"` // unique per device profile, the service local id is CommandServiceLocalIdPrefix + name
+	Description string                 `json:"description,omitempty"`
+	FPort       uint32                 `json:"f_port"`
+	Confirmed   bool                   `json:"confirmed"`
+	Encoder     DownlinkEncoder        `json:"encoder"`           // defaults to codec
+	Payload     string                 `json:"payload,omitempty"` // hex encoded, only used by the static encoder
+	Inputs      []DownlinkCommandInput `json:"inputs,omitempty"`
+}
+
+type DownlinkCommandInput struct {
+	Name             string `json:"name"`
+	Type             string `json:"type"` // platform type, one of https://schema.org/Text, https://schema.org/Integer, https://schema.org/Float or https://schema.org/Boolean
+	CharacteristicId string `json:"characteristic_id,omitempty"`
+	FunctionId       string `json:"function_id,omitempty"`
+	AspectId         string `json:"aspect_id,omitempty"`
+}
+
+// AnnotationRule assigns semantic ids to content variables, which are inferred from uplinks and not annotated yet.
+type AnnotationRule struct {
+	Pattern          string      `json:"pattern"`          // regular expression matched against the path of the content variable below root, e.g. "^object\\.temperature$"
+	Vendor           string      `json:"vendor,omitempty"` // optional regular expression matched against the vendor of the device profile
+	Model            string      `json:"model,omitempty"`  // optional regular expression matched against the device model, or the profile name if no model is known
+	Type             models.Type `json:"type,omitempty"`   // optional, only content variables of this type are annotated
+	CharacteristicId string      `json:"characteristic_id,omitempty"`
+	FunctionId       string      `json:"function_id,omitempty"`
+	AspectId         string      `json:"aspect_id,omitempty"`
+	UnitReference    string      `json:"unit_reference,omitempty"` // name of a sibling content variable holding the unit
+}
+
+// BufferedEvent is an uplink or status event persisted in the uplink buffer until it has been handled.
+type BufferedEvent struct {
+	UserId          string            `json:"user_id"`
+	LocalDeviceId   string            `json:"local_device_id"`
+	LocalServiceId  string            `json:"local_service_id"`
+	Payload         json.RawMessage   `json:"payload"`
+	Time            time.Time         `json:"time"`
+	RxInfo          []json.RawMessage `json:"rx_info,omitempty"` // protojson encoded gw.UplinkRxInfo
+	DeviceProfileId string            `json:"device_profile_id"`
+	DeduplicationId string            `json:"deduplication_id,omitempty"`
+}
+
+type DeadLetter struct {
+	Id       string        `json:"id"`
+	Event    BufferedEvent `json:"event"`
+	Error    string        `json:"error"`
+	Attempts int           `json:"attempts"`
+	FailedAt time.Time     `json:"failed_at"`
+}
+
+type DeadLetterReplay struct {
+	Replayed int `json:"replayed"`
+}
+
+// SinkEvent is written by the file and webhook event sinks.
+type SinkEvent struct {
+	Time           string          `json:"time"`
+	UserId         string          `json:"user_id"`
+	DeviceId       string          `json:"device_id"`
+	LocalDeviceId  string          `json:"local_device_id"`
+	ServiceId      string          `json:"service_id"`
+	LocalServiceId string          `json:"local_service_id"`
+	Data           json.RawMessage `json:"data"`
+}
+
+// WebhookSubscription receives the normalized events of all devices of a user. The secret is only returned on creation.
+type WebhookSubscription struct {
+	Id        string    `json:"id"`
+	Url       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`     // key of the hmac signature, generated if empty
+	Events    []string  `json:"events,omitempty"`     // "up", "join", "status" and/or "location", all events if empty
+	DeviceIds []string  `json:"device_ids,omitempty"` // optional filter by platform device id
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookEvent is posted to webhook subscriptions after an event has been handled.
+type WebhookEvent struct {
+	Id             string          `json:"id"`
+	Type           string          `json:"type"`
+	Time           time.Time       `json:"time"`
+	DeviceId       string          `json:"device_id"`
+	DeviceName     string          `json:"device_name"`
+	LocalDeviceId  string          `json:"local_device_id"`
+	ServiceId      string          `json:"service_id,omitempty"`
+	LocalServiceId string          `json:"local_service_id,omitempty"`
+	Payload        json.RawMessage `json:"payload,omitempty"`
+	RxInfo         []WebhookRxInfo `json:"rx_info,omitempty"`
+}
+
+type WebhookRxInfo struct {
+	GatewayId string  `json:"gateway_id"`
+	Rssi      int32   `json:"rssi"`
+	Snr       float32 `json:"snr"`
+	Channel   uint32  `json:"channel"`
+}
+
+type WebhookDelivery struct {
+	Time       time.Time `json:"time"`
+	EventId    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	DeviceId   string    `json:"device_id"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Success    bool      `json:"success"`
+}
+
+// LocationEstimate is published as location service of devices with a location estimator.
+type LocationEstimate struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Accuracy  float64 `json:"accuracy"` // estimated error radius in meters
+	Method    string  `json:"method"`   // estimator used, see LocationEstimatorCentroid and LocationEstimatorMultilateration
+	Gateways  int     `json:"gateways"` // number of receiving gateways with known position
+}
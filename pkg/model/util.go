@@ -47,3 +47,23 @@ func UpsertGatewayAttribute(attribute models.Attribute, gateway *models.Hub) boo
 	gateway.Attributes = append(gateway.Attributes, attribute)
 	return true
 }
+
+func RemoveDeviceAttribute(key string, device *models.Device) bool {
+	for i := range device.Attributes {
+		if device.Attributes[i].Key == key {
+			device.Attributes = append(device.Attributes[:i], device.Attributes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func RemoveGatewayAttribute(key string, gateway *models.Hub) bool {
+	for i := range gateway.Attributes {
+		if gateway.Attributes[i].Key == key {
+			gateway.Attributes = append(gateway.Attributes[:i], gateway.Attributes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
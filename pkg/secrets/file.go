@@ -0,0 +1,218 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileBackend keeps all secrets in a single json file. Secrets are already encrypted by the Store, the file is written with owner permissions only.
+// Audit entries are appended to a separate json lines file, so reading secrets never rewrites the secrets file.
+type FileBackend struct {
+	path       string
+	mux        sync.Mutex
+	data       fileData
+	audit      [][]byte // newest first
+	auditLines int      // lines in the audit file, which is compacted once it holds twice the kept entries
+}
+
+type fileData struct {
+	Secrets map[string][]byte `json:"secrets"`
+	Audit   [][]byte          `json:"audit,omitempty"` // only read to migrate stores written by previous versions
+}
+
+func NewFileBackend(path string) (*FileBackend, error) {
+	f := &FileBackend{
+		path: path,
+		data: fileData{Secrets: map[string][]byte{}},
+	}
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		err = json.Unmarshal(b, &f.data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if f.data.Secrets == nil {
+		f.data.Secrets = map[string][]byte{}
+	}
+	b, err = os.ReadFile(f.auditPath())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if len(line) > 0 {
+			f.audit = append([][]byte{line}, f.audit...)
+			f.auditLines++
+		}
+	}
+	if len(f.data.Audit) > 0 {
+		f.audit = append(f.audit, f.data.Audit...)
+		f.data.Audit = nil
+		err = f.compactAudit()
+		if err == nil {
+			err = f.write()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(f.audit) > auditLength {
+		f.audit = f.audit[:auditLength]
+	}
+	return f, nil
+}
+
+func (f *FileBackend) Get(_ context.Context, id string) ([]byte, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	value, ok := f.data.Secrets[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return value, nil
+}
+
+func (f *FileBackend) Set(_ context.Context, id string, value []byte) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	previous, existed := f.data.Secrets[id]
+	f.data.Secrets[id] = value
+	err := f.write()
+	if err != nil {
+		if existed {
+			f.data.Secrets[id] = previous
+		} else {
+			delete(f.data.Secrets, id)
+		}
+	}
+	return err
+}
+
+func (f *FileBackend) Delete(_ context.Context, id string) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	previous, existed := f.data.Secrets[id]
+	if !existed {
+		return nil
+	}
+	delete(f.data.Secrets, id)
+	err := f.write()
+	if err != nil {
+		f.data.Secrets[id] = previous
+	}
+	return err
+}
+
+func (f *FileBackend) List(_ context.Context) ([]string, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	ids := make([]string, 0, len(f.data.Secrets))
+	for id := range f.data.Secrets {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (f *FileBackend) AppendAudit(_ context.Context, entry []byte) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	file, err := os.OpenFile(f.auditPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(entry, '\n'))
+	closeErr := file.Close()
+	if err != nil || closeErr != nil {
+		return errors.Join(err, closeErr)
+	}
+	f.auditLines++
+	f.audit = append([][]byte{entry}, f.audit...)
+	if len(f.audit) > auditLength {
+		f.audit = f.audit[:auditLength]
+	}
+	if f.auditLines > 2*auditLength {
+		return f.compactAudit()
+	}
+	return nil
+}
+
+func (f *FileBackend) ListAudit(_ context.Context, limit int64, offset int64) ([][]byte, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if offset >= int64(len(f.audit)) {
+		return [][]byte{}, nil
+	}
+	end := min(offset+limit, int64(len(f.audit)))
+	return append([][]byte{}, f.audit[offset:end]...), nil
+}
+
+func (f *FileBackend) auditPath() string {
+	return f.path + ".audit"
+}
+
+// compactAudit rewrites the audit file with the kept entries only.
+func (f *FileBackend) compactAudit() error {
+	if len(f.audit) > auditLength {
+		f.audit = f.audit[:auditLength]
+	}
+	b := []byte{}
+	for i := len(f.audit) - 1; i >= 0; i-- {
+		b = append(append(b, f.audit[i]...), '\n')
+	}
+	err := writeFileAtomic(f.auditPath(), b)
+	if err != nil {
+		return err
+	}
+	f.auditLines = len(f.audit)
+	return nil
+}
+
+func (f *FileBackend) write() error {
+	b, err := json.Marshal(f.data)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(f.path, b)
+}
+
+// writeFileAtomic replaces the file with a renamed temporary file, so a crash never leaves a partially written file behind.
+func writeFileAtomic(path string, b []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(b)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	closeErr := tmp.Close()
+	if err != nil || closeErr != nil {
+		return errors.Join(err, closeErr)
+	}
+	return os.Rename(tmp.Name(), path)
+}
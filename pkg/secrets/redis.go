@@ -0,0 +1,79 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secrets
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/go-redis/redis/v8"
+)
+
+type RedisBackend struct {
+	rdb *redis.Client
+}
+
+func NewRedisBackend(rdb *redis.Client) *RedisBackend {
+	return &RedisBackend{rdb: rdb}
+}
+
+func (r *RedisBackend) Get(ctx context.Context, id string) ([]byte, error) {
+	b, err := r.rdb.Get(ctx, model.RedisKeyPrefixSecret+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	return b, err
+}
+
+func (r *RedisBackend) Set(ctx context.Context, id string, value []byte) error {
+	return r.rdb.Set(ctx, model.RedisKeyPrefixSecret+id, value, 0).Err()
+}
+
+func (r *RedisBackend) Delete(ctx context.Context, id string) error {
+	return r.rdb.Del(ctx, model.RedisKeyPrefixSecret+id).Err()
+}
+
+func (r *RedisBackend) List(ctx context.Context) (ids []string, err error) {
+	iter := r.rdb.Scan(ctx, 0, model.RedisKeyPrefixSecret+"*", 0).Iterator()
+	for iter.Next(ctx) {
+		ids = append(ids, strings.TrimPrefix(iter.Val(), model.RedisKeyPrefixSecret))
+	}
+	return ids, iter.Err()
+}
+
+func (r *RedisBackend) AppendAudit(ctx context.Context, entry []byte) error {
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, model.RedisKeySecretAudit, entry)
+		pipe.LTrim(ctx, model.RedisKeySecretAudit, 0, auditLength-1)
+		return nil
+	})
+	return err
+}
+
+func (r *RedisBackend) ListAudit(ctx context.Context, limit int64, offset int64) ([][]byte, error) {
+	entries, err := r.rdb.LRange(ctx, model.RedisKeySecretAudit, offset, offset+limit-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	result := make([][]byte, len(entries))
+	for i, entry := range entries {
+		result[i] = []byte(entry)
+	}
+	return result, nil
+}
@@ -0,0 +1,310 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/configuration"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/log"
+	"github.com/SENERGY-Platform/lorawan-platform-connector/pkg/model"
+	"github.com/go-redis/redis/v8"
+)
+
+const masterKeyLength = 32
+const dataKeyLength = 32
+const auditLength = 10000
+
+var ErrNotFound = errors.New("secret not found")
+
+type Backend interface {
+	Get(ctx context.Context, id string) ([]byte, error) // returns ErrNotFound for unknown ids
+	Set(ctx context.Context, id string, value []byte) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context) ([]string, error)
+	AppendAudit(ctx context.Context, entry []byte) error // keeps the newest auditLength entries
+	ListAudit(ctx context.Context, limit int64, offset int64) ([][]byte, error)
+}
+
+// Store encrypts each secret with its own data key. The data key is encrypted with a master key from the configuration,
+// so rotating the master key only needs to re-encrypt the data keys.
+type Store struct {
+	backend     Backend
+	masterKeys  map[string][]byte
+	activeKeyId string
+}
+
+type envelope struct {
+	MasterKeyId string    `json:"master_key_id"`
+	WrappedKey  []byte    `json:"wrapped_key"` // data key encrypted with the master key
+	Ciphertext  []byte    `json:"ciphertext"`  // secret encrypted with the data key
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// New creates the store configured by SecretStoreBackend. Returns nil, if no backend is configured.
+func New(config configuration.Config, rdb *redis.Client) (*Store, error) {
+	var backend Backend
+	var err error
+	switch config.SecretStoreBackend {
+	case "":
+		return nil, nil
+	case "redis":
+		backend = NewRedisBackend(rdb)
+	case "file":
+		backend, err = NewFileBackend(config.SecretStoreFile)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown secret store backend %s", config.SecretStoreBackend)
+	}
+	return NewStore(backend, config.SecretStoreMasterKeys)
+}
+
+// NewStore parses the master keys in the format <id>:<base64 encoded key>. The first key is used to encrypt new secrets,
+// the other keys are only used to decrypt existing secrets until they have been rotated.
+func NewStore(backend Backend, masterKeys []string) (*Store, error) {
+	if len(masterKeys) == 0 {
+		return nil, fmt.Errorf("secret store needs at least one master key")
+	}
+	store := &Store{
+		backend:    backend,
+		masterKeys: map[string][]byte{},
+	}
+	for i, masterKey := range masterKeys {
+		id, encoded, ok := strings.Cut(masterKey, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("master key %d is not in the format <id>:<base64 encoded key>", i)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("unable to decode master key %s", id), err)
+		}
+		if len(key) != masterKeyLength {
+			return nil, fmt.Errorf("master key %s must be %d bytes", id, masterKeyLength)
+		}
+		if _, ok := store.masterKeys[id]; ok {
+			return nil, fmt.Errorf("duplicate master key id %s", id)
+		}
+		store.masterKeys[id] = key
+		if i == 0 {
+			store.activeKeyId = id
+		}
+	}
+	return store, nil
+}
+
+func (s *Store) Put(ctx context.Context, id string, value []byte, actor string) error {
+	dataKey := make([]byte, dataKeyLength)
+	_, err := rand.Read(dataKey)
+	if err != nil {
+		return err
+	}
+	ciphertext, err := seal(dataKey, value, id)
+	if err != nil {
+		return err
+	}
+	wrappedKey, err := seal(s.masterKeys[s.activeKeyId], dataKey, id)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(envelope{
+		MasterKeyId: s.activeKeyId,
+		WrappedKey:  wrappedKey,
+		Ciphertext:  ciphertext,
+		UpdatedAt:   time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	err = s.backend.Set(ctx, id, b)
+	if err != nil {
+		return err
+	}
+	s.audit(ctx, model.SecretActionPut, id, actor)
+	return nil
+}
+
+func (s *Store) Get(ctx context.Context, id string, actor string) ([]byte, error) {
+	env, err := s.read(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := s.unwrap(env, id)
+	if err != nil {
+		return nil, err
+	}
+	value, err := open(dataKey, env.Ciphertext, id)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("unable to decrypt secret %s", id), err)
+	}
+	s.audit(ctx, model.SecretActionGet, id, actor)
+	return value, nil
+}
+
+func (s *Store) Delete(ctx context.Context, id string, actor string) error {
+	err := s.backend.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.audit(ctx, model.SecretActionDelete, id, actor)
+	return nil
+}
+
+// RotateMasterKey re-encrypts the data keys of all secrets, which are not encrypted with the active master key yet.
+// Returns the number of rotated secrets.
+func (s *Store) RotateMasterKey(ctx context.Context, actor string) (rotated int, err error) {
+	ids, err := s.backend.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		env, err := s.read(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			continue // deleted in the meantime
+		}
+		if err != nil {
+			return rotated, err
+		}
+		if env.MasterKeyId == s.activeKeyId {
+			continue
+		}
+		dataKey, err := s.unwrap(env, id)
+		if err != nil {
+			return rotated, err
+		}
+		env.WrappedKey, err = seal(s.masterKeys[s.activeKeyId], dataKey, id)
+		if err != nil {
+			return rotated, err
+		}
+		env.MasterKeyId = s.activeKeyId
+		env.UpdatedAt = time.Now()
+		b, err := json.Marshal(env)
+		if err != nil {
+			return rotated, err
+		}
+		err = s.backend.Set(ctx, id, b)
+		if err != nil {
+			return rotated, err
+		}
+		s.audit(ctx, model.SecretActionRotate, id, actor)
+		rotated++
+	}
+	return rotated, nil
+}
+
+// ListAudit returns audit entries, newest first.
+func (s *Store) ListAudit(ctx context.Context, limit int64, offset int64) ([]model.SecretAuditEntry, error) {
+	entries, err := s.backend.ListAudit(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	result := []model.SecretAuditEntry{}
+	for _, entry := range entries {
+		var auditEntry model.SecretAuditEntry
+		err = json.Unmarshal(entry, &auditEntry)
+		if err != nil {
+			log.Logger.Error("unable to unmarshal secret audit entry", attributes.ErrorKey, err)
+			continue
+		}
+		result = append(result, auditEntry)
+	}
+	return result, nil
+}
+
+func (s *Store) read(ctx context.Context, id string) (env envelope, err error) {
+	b, err := s.backend.Get(ctx, id)
+	if err != nil {
+		return env, err
+	}
+	err = json.Unmarshal(b, &env)
+	return env, err
+}
+
+func (s *Store) unwrap(env envelope, id string) ([]byte, error) {
+	masterKey, ok := s.masterKeys[env.MasterKeyId]
+	if !ok {
+		return nil, fmt.Errorf("secret %s is encrypted with unknown master key %s", id, env.MasterKeyId)
+	}
+	dataKey, err := open(masterKey, env.WrappedKey, id)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("unable to decrypt data key of secret %s", id), err)
+	}
+	return dataKey, nil
+}
+
+// audit failures are logged only, as they must not prevent access to secrets
+func (s *Store) audit(ctx context.Context, action model.SecretAction, id string, actor string) {
+	b, err := json.Marshal(model.SecretAuditEntry{
+		Time:        time.Now(),
+		Action:      action,
+		SecretId:    id,
+		Actor:       actor,
+		MasterKeyId: s.activeKeyId,
+	})
+	if err != nil {
+		log.Logger.Error("unable to marshal secret audit entry", attributes.ErrorKey, err)
+		return
+	}
+	err = s.backend.AppendAudit(ctx, b)
+	if err != nil {
+		log.Logger.Error("unable to write secret audit entry", attributes.ErrorKey, err, "secret_id", id, "action", action)
+	}
+}
+
+// seal encrypts with AES-GCM, binding the ciphertext to the secret id. The nonce is prepended to the ciphertext.
+func seal(key []byte, plaintext []byte, id string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, []byte(id)), nil
+}
+
+func open(key []byte, ciphertext []byte, id string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	return gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], []byte(id))
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
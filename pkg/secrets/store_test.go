@@ -0,0 +1,313 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+// memoryBackend keeps secrets in memory, to test the store without redis or files
+type memoryBackend struct {
+	mux     sync.Mutex
+	secrets map[string][]byte
+	audit   [][]byte
+}
+
+func newMemoryBackend() *memoryBackend {
+	return &memoryBackend{secrets: map[string][]byte{}}
+}
+
+func (b *memoryBackend) Get(_ context.Context, id string) ([]byte, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	value, ok := b.secrets[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return bytes.Clone(value), nil
+}
+
+func (b *memoryBackend) Set(_ context.Context, id string, value []byte) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.secrets[id] = bytes.Clone(value)
+	return nil
+}
+
+func (b *memoryBackend) Delete(_ context.Context, id string) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	delete(b.secrets, id)
+	return nil
+}
+
+func (b *memoryBackend) List(_ context.Context) ([]string, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	ids := []string{}
+	for id := range b.secrets {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (b *memoryBackend) AppendAudit(_ context.Context, entry []byte) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.audit = append(b.audit, entry)
+	return nil
+}
+
+func (b *memoryBackend) ListAudit(_ context.Context, limit int64, offset int64) ([][]byte, error) {
+	return nil, nil
+}
+
+func testMasterKey(t *testing.T, id string) string {
+	key := make([]byte, masterKeyLength)
+	_, err := rand.Read(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id + ":" + base64.StdEncoding.EncodeToString(key)
+}
+
+func readEnvelope(t *testing.T, backend *memoryBackend, id string) envelope {
+	b, err := backend.Get(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var env envelope
+	err = json.Unmarshal(b, &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return env
+}
+
+func writeEnvelope(t *testing.T, backend *memoryBackend, id string, env envelope) {
+	b, err := json.Marshal(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = backend.Set(context.Background(), id, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewStore(t *testing.T) {
+	valid := testMasterKey(t, "k1")
+	tests := []struct {
+		name       string
+		masterKeys []string
+		err        bool
+	}{
+		{name: "single key", masterKeys: []string{valid}},
+		{name: "multiple keys", masterKeys: []string{valid, testMasterKey(t, "k2")}},
+		{name: "no keys", masterKeys: []string{}, err: true},
+		{name: "missing id", masterKeys: []string{":" + base64.StdEncoding.EncodeToString(make([]byte, masterKeyLength))}, err: true},
+		{name: "missing separator", masterKeys: []string{base64.StdEncoding.EncodeToString(make([]byte, masterKeyLength))}, err: true},
+		{name: "invalid base64", masterKeys: []string{"k1:not base64!"}, err: true},
+		{name: "short key", masterKeys: []string{"k1:" + base64.StdEncoding.EncodeToString(make([]byte, 16))}, err: true},
+		{name: "duplicate id", masterKeys: []string{valid, valid}, err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store, err := NewStore(newMemoryBackend(), test.masterKeys)
+			if test.err {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if store.activeKeyId != "k1" {
+				t.Fatalf("expected first key to be active, got %s", store.activeKeyId)
+			}
+		})
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		value []byte
+	}{
+		{name: "text", value: []byte("00112233445566778899aabbccddeeff")},
+		{name: "binary", value: []byte{0, 1, 2, 255, 254, 0}},
+		{name: "empty", value: []byte{}},
+	}
+	store, err := NewStore(newMemoryBackend(), []string{testMasterKey(t, "k1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := store.Put(ctx, test.name, test.value, "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			value, err := store.Get(ctx, test.name, "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(value, test.value) {
+				t.Fatalf("expected %x, got %x", test.value, value)
+			}
+		})
+	}
+}
+
+func TestStoreTamperDetection(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(env *envelope)
+	}{
+		{name: "ciphertext", tamper: func(env *envelope) { env.Ciphertext[len(env.Ciphertext)-1] ^= 1 }},
+		{name: "ciphertext nonce", tamper: func(env *envelope) { env.Ciphertext[0] ^= 1 }},
+		{name: "truncated ciphertext", tamper: func(env *envelope) { env.Ciphertext = env.Ciphertext[:4] }},
+		{name: "wrapped key", tamper: func(env *envelope) { env.WrappedKey[len(env.WrappedKey)-1] ^= 1 }},
+		{name: "unknown master key id", tamper: func(env *envelope) { env.MasterKeyId = "unknown" }},
+	}
+	backend := newMemoryBackend()
+	store, err := NewStore(backend, []string{testMasterKey(t, "k1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := store.Put(ctx, test.name, []byte("secret"), "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			env := readEnvelope(t, backend, test.name)
+			test.tamper(&env)
+			writeEnvelope(t, backend, test.name, env)
+			_, err = store.Get(ctx, test.name, "test")
+			if err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+
+	t.Run("swapped secret id", func(t *testing.T) {
+		err := store.Put(ctx, "a", []byte("secret a"), "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the ciphertext is bound to the secret id and may not be moved to another id
+		b, err := backend.Get(ctx, "a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = backend.Set(ctx, "b", b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = store.Get(ctx, "b", "test")
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := store.Get(ctx, "missing", "test")
+		if err != ErrNotFound {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestStoreRotateMasterKey(t *testing.T) {
+	ctx := context.Background()
+	backend := newMemoryBackend()
+	k1 := testMasterKey(t, "k1")
+	k2 := testMasterKey(t, "k2")
+	values := map[string][]byte{
+		"a": []byte("secret a"),
+		"b": []byte("secret b"),
+	}
+
+	oldStore, err := NewStore(backend, []string{k1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, value := range values {
+		err = oldStore.Put(ctx, id, value, "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store, err := NewStore(backend, []string{k2, k1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Put(ctx, "c", []byte("secret c"), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values["c"] = []byte("secret c")
+
+	rotated, err := store.RotateMasterKey(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated != 2 {
+		t.Fatalf("expected 2 rotated secrets, got %d", rotated)
+	}
+	rotated, err = store.RotateMasterKey(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated != 0 {
+		t.Fatalf("expected second rotation to be a no-op, got %d", rotated)
+	}
+
+	newStore, err := NewStore(backend, []string{k2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, value := range values {
+		env := readEnvelope(t, backend, id)
+		if env.MasterKeyId != "k2" {
+			t.Fatalf("expected %s to be encrypted with k2, got %s", id, env.MasterKeyId)
+		}
+		// the old master key is no longer needed after the rotation
+		actual, err := newStore.Get(ctx, id, "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(actual, value) {
+			t.Fatalf("expected %s for %s, got %s", value, id, actual)
+		}
+	}
+	_, err = oldStore.Get(ctx, "a", "test")
+	if err == nil {
+		t.Fatal("expected old store without k2 to fail")
+	}
+}